@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/MinterTeam/explorer-genesis-uploader/core"
+	"github.com/MinterTeam/explorer-genesis-uploader/env"
+	"os"
+	"strings"
+)
+
+var commands = []command{
+	{
+		name:    "upload",
+		summary: "Upload genesis into an empty explorer DB",
+		help:    "Loads genesis from the node or a file, validates it and uploads it into an empty explorer DB.",
+		db:      true,
+		source:  true,
+		setup: func(fs *flag.FlagSet) func(cfg env.Config) int {
+			return func(cfg env.Config) int {
+				uploader := core.New(cfg)
+				defer uploader.Close()
+				return exitCode(uploader.Do())
+			}
+		},
+	},
+	{
+		name:    "verify",
+		summary: "Compare uploaded data with genesis",
+		help:    "Compares counts and sums stored in DB with genesis, lists every discrepancy and exits with code 7 if any is found.",
+		db:      true,
+		source:  true,
+		setup: func(fs *flag.FlagSet) func(cfg env.Config) int {
+			return func(cfg env.Config) int {
+				uploader := core.New(cfg)
+				defer uploader.Close()
+				discrepancies, err := uploader.Verify()
+				if err != nil {
+					return exitCode(err)
+				}
+				if len(discrepancies) > 0 {
+					return exitDiscrepancies
+				}
+				return exitOK
+			}
+		},
+	},
+	{
+		name:    "diff",
+		summary: "Preview changes genesis makes to explorer DB",
+		help:    "Compares addresses, coins, validators, balances, stakes and pools stored in DB with genesis and shows added, removed and changed entities with per-coin totals.",
+		db:      true,
+		source:  true,
+		setup: func(fs *flag.FlagSet) func(cfg env.Config) int {
+			format := fs.String("format", "text", "Output format: text or json")
+			limit := fs.Int("limit", 100, "Changes listed in text output, -1 lists all")
+			return func(cfg env.Config) int {
+				uploader := core.New(stdoutForData(cfg))
+				defer uploader.Close()
+				diff, err := uploader.Diff()
+				if err != nil {
+					return exitCode(err)
+				}
+				return writeDiff(diff, *format, *limit)
+			}
+		},
+	},
+	{
+		name:    "diff genesis",
+		summary: "Compare two genesis sources",
+		help: "Compares two genesis sources and shows added, removed and changed addresses, coins, candidates, balances, stakes, pools and orders with per-coin totals.\n" +
+			"A source is file:<path> or grpc:<address>.",
+		setup: func(fs *flag.FlagSet) func(cfg env.Config) int {
+			oldSource := fs.String("old", "", "Old genesis source, required")
+			newSource := fs.String("new", "", "New genesis source, required")
+			format := fs.String("format", "text", "Output format: text or json")
+			limit := fs.Int("limit", 100, "Changes listed in text output, -1 lists all")
+			return func(cfg env.Config) int {
+				if *oldSource == "" || *newSource == "" {
+					fmt.Fprintln(os.Stderr, "-old and -new are required")
+					return exitError
+				}
+				uploader := core.NewOffline(stdoutForData(cfg))
+				defer uploader.Close()
+				diff, err := uploader.DiffGenesis(*oldSource, *newSource)
+				if err != nil {
+					return exitCode(err)
+				}
+				return writeDiff(diff, *format, *limit)
+			}
+		},
+	},
+	{
+		name:    "inspect",
+		summary: "Show genesis statistics",
+		help: "Shows statistics of genesis without touching DB: counts per entity, multisig accounts, supply distribution of every coin,\n" +
+			"top holders per coin, top validators by stake and pools with the most orders.",
+		source: true,
+		setup: func(fs *flag.FlagSet) func(cfg env.Config) int {
+			format := fs.String("format", "text", "Output format: text or json")
+			top := fs.Int("top", 10, "Entries listed in top holders, validators and pools, -1 lists all")
+			return func(cfg env.Config) int {
+				uploader := core.NewOffline(stdoutForData(cfg))
+				defer uploader.Close()
+				inspection, err := uploader.Inspect(*top)
+				if err != nil {
+					return exitCode(err)
+				}
+				switch *format {
+				case "json":
+					return writeJSON("", inspection)
+				case "text":
+					if err := inspection.WriteSummary(os.Stdout); err != nil {
+						fmt.Fprintln(os.Stderr, err)
+						return exitError
+					}
+					return exitOK
+				default:
+					fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+					return exitError
+				}
+			}
+		},
+	},
+	{
+		name:    "export",
+		summary: "Export uploaded data as genesis",
+		help: "Reconstructs genesis file from addresses, balances, coins, validators, stakes, unbonds, pools and orders stored in DB.\n" +
+			"The file can be uploaded with -file or compared with diff genesis, data the explorer does not keep is left empty.",
+		db: true,
+		setup: func(fs *flag.FlagSet) func(cfg env.Config) int {
+			output := fs.String("output", "", "Path of the exported file, stdout if empty")
+			return func(cfg env.Config) int {
+				uploader := core.New(stdoutForData(cfg))
+				defer uploader.Close()
+				genesis, err := uploader.Export()
+				if err != nil {
+					return exitCode(err)
+				}
+				return writeJSON(*output, genesis)
+			}
+		},
+	},
+	{
+		name:    "export rows",
+		summary: "Write rows of genesis as CSV or NDJSON files",
+		help: "Extracts genesis without DB and writes rows the upload would insert into an empty DB, one file per table: addresses, coins, validators,\n" +
+			"balances, stakes, unbonds, liquidity_pools, address_liquidity_pools and orders. Ids DB would generate are assigned in insertion order.",
+		source: true,
+		setup: func(fs *flag.FlagSet) func(cfg env.Config) int {
+			dir := fs.String("dir", "rows", "Directory of the written files")
+			format := fs.String("format", core.RowsFormatCSV, "Output format: csv or ndjson")
+			return func(cfg env.Config) int {
+				uploader := core.NewOffline(cfg)
+				defer uploader.Close()
+				return exitCode(uploader.ExportRows(*dir, *format))
+			}
+		},
+	},
+	{
+		name:    "export sql",
+		summary: "Write genesis upload as a psql script",
+		help: "Extracts genesis without DB and writes a script with COPY blocks and sequence setval calls inserting the same rows with the same ids\n" +
+			"as an upload into an empty DB, to be reviewed and applied with psql -v ON_ERROR_STOP=1 -f <file>.",
+		source: true,
+		setup: func(fs *flag.FlagSet) func(cfg env.Config) int {
+			output := fs.String("output", "", "Path of the script, stdout if empty")
+			return func(cfg env.Config) int {
+				uploader := core.NewOffline(stdoutForData(cfg))
+				defer uploader.Close()
+				return exitCode(uploader.ExportSQL(*output))
+			}
+		},
+	},
+	{
+		name:    "wipe",
+		summary: "Remove uploaded data from DB",
+		help: "Deletes rows of the tables filled by upload and restarts their sequences, so the upload can be run again.\n" +
+			"The chain id must match the recorded upload and -confirm must be given.",
+		db: true,
+		setup: func(fs *flag.FlagSet) func(cfg env.Config) int {
+			chainID := fs.String("chain-id", "", "Chain id of the uploaded genesis, required")
+			confirm := fs.Bool("confirm", false, "Confirm deletion of uploaded data")
+			return func(cfg env.Config) int {
+				if *chainID == "" {
+					fmt.Fprintln(os.Stderr, "-chain-id is required")
+					return exitError
+				}
+				if !*confirm {
+					fmt.Fprintf(os.Stderr, "wipe deletes all rows of %s, rerun with -confirm\n", strings.Join(core.WipeTables, ", "))
+					return exitError
+				}
+				uploader := core.New(cfg)
+				defer uploader.Close()
+				return exitCode(uploader.Wipe(*chainID))
+			}
+		},
+	},
+	{
+		name:    "migrate",
+		summary: "Apply schema changes the uploader relies on",
+		help:    "Applies idempotent migrations from database/migrations on top of database/db.sql.",
+		db:      true,
+		setup: func(fs *flag.FlagSet) func(cfg env.Config) int {
+			return func(cfg env.Config) int {
+				uploader := core.New(cfg)
+				defer uploader.Close()
+				return exitCode(uploader.Migrate())
+			}
+		},
+	},
+	{
+		name:    "config check",
+		summary: "Validate config and print it",
+		help:    "Validates config loaded from defaults, toml, environment and flags and prints it as toml with the password hidden.",
+		db:      true,
+		source:  true,
+		setup: func(fs *flag.FlagSet) func(cfg env.Config) int {
+			return func(cfg env.Config) int {
+				if cfg.PostgresPassword != "" {
+					cfg.PostgresPassword = "******"
+				}
+				if err := toml.NewEncoder(os.Stdout).Encode(cfg); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					return exitError
+				}
+				fmt.Fprintln(os.Stderr, "config is valid")
+				return exitOK
+			}
+		},
+	},
+}
+
+// stdoutForData moves logs from stdout to stderr, so command output can be piped
+func stdoutForData(cfg env.Config) env.Config {
+	if cfg.LogOutput == "stdout" {
+		cfg.LogOutput = "stderr"
+	}
+	return cfg
+}
+
+// writeJSON writes indented json to the file or stdout if path is empty
+func writeJSON(path string, v interface{}) int {
+	out := os.Stdout
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		defer f.Close()
+		out = f
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	return exitOK
+}
+
+func writeDiff(diff *core.Diff, format string, limit int) int {
+	var err error
+	switch format {
+	case "json":
+		return writeJSON("", diff)
+	case "text":
+		err = diff.WriteSummary(os.Stdout, limit)
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	return exitOK
+}
@@ -1,74 +1,122 @@
 package main
 
 import (
+	"errors"
 	"flag"
-	"github.com/BurntSushi/toml"
+	"fmt"
 	"github.com/MinterTeam/explorer-genesis-uploader/core"
 	"github.com/MinterTeam/explorer-genesis-uploader/env"
-	"github.com/joho/godotenv"
 	"os"
-	"strconv"
+	"strings"
 )
 
-var cfg = flag.String(`config`, "", `Path to config`)
+// Process exit codes
+const (
+	exitOK                = 0
+	exitError             = 1
+	exitSourceUnavailable = 2
+	exitDBUnavailable     = 3
+	exitDBNotEmpty        = 4
+	exitValidationFailed  = 5
+	exitDBWrite           = 6
+	exitDiscrepancies     = 7
+	exitWipeRefused       = 8
+)
+
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, core.ErrSourceUnavailable):
+		return exitSourceUnavailable
+	case errors.Is(err, core.ErrDBUnavailable):
+		return exitDBUnavailable
+	case errors.Is(err, core.ErrDBNotEmpty):
+		return exitDBNotEmpty
+	case errors.Is(err, core.ErrValidationFailed):
+		return exitValidationFailed
+	case errors.Is(err, core.ErrDBWrite):
+		return exitDBWrite
+	case errors.Is(err, core.ErrWipeRefused):
+		return exitWipeRefused
+	default:
+		return exitError
+	}
+}
+
+// command is a subcommand of the uploader, setup registers its own flags
+// and returns the action run with loaded config
+type command struct {
+	name    string
+	summary string
+	help    string
+	db      bool
+	source  bool
+	setup   func(fs *flag.FlagSet) func(cfg env.Config) int
+}
+
+const binary = "explorer_genesis_uploader"
 
 func main() {
-	flag.Parse()
+	cmd, args := findCommand(os.Args[1:])
+	if cmd == nil {
+		usage()
+		os.Exit(exitError)
+	}
 
-	var environment env.Config
+	fs := flag.NewFlagSet(cmd.name, flag.ExitOnError)
+	configPath := fs.String("config", "", "Path to toml config")
+	loader := env.NewLoader(fs)
+	run := cmd.setup(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s [flags]\n\n%s\n\nFlags:\n", binary, cmd.name, cmd.help)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
 
-	if *cfg != "" {
-		if _, err := toml.DecodeFile(*cfg, &environment); err != nil {
-			panic(err)
-		}
-	} else {
-		err := godotenv.Load()
-		if err != nil {
-			println("Error loading .env file")
-		}
-		addressChunkSize, err := strconv.ParseUint(os.Getenv("APP_ADDRESS_CHUNK_SIZE"), 10, 64)
-		if err != nil {
-			println(err)
-		}
-		coinsChunkSize, err := strconv.ParseUint(os.Getenv("APP_COINS_CHUNK_SIZE"), 10, 64)
-		if err != nil {
-			println(err)
-		}
-		balanceChunkSize, err := strconv.ParseUint(os.Getenv("APP_BALANCES_CHUNK_SIZE"), 10, 64)
-		if err != nil {
-			println(err)
-		}
-		stakeChunkSize, err := strconv.ParseUint(os.Getenv("APP_STAKE_CHUNK_SIZE"), 10, 64)
-		if err != nil {
-			println(err)
-		}
-		validatorChunkSize, err := strconv.ParseUint(os.Getenv("APP_VALIDATORS_CHUNK_SIZE"), 10, 64)
-		if err != nil {
-			println(err)
+	cfg, err := loader.Load(*configPath)
+	var problems env.Problems
+	if err != nil && !errors.As(err, &problems) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitError)
+	}
+	if cmd.db {
+		problems = append(problems, cfg.ValidateDB()...)
+	}
+	if cmd.source {
+		problems = append(problems, cfg.ValidateSource()...)
+	}
+	if len(problems) > 0 {
+		fmt.Fprintln(os.Stderr, problems)
+		os.Exit(exitError)
+	}
+
+	os.Exit(run(cfg))
+}
+
+// findCommand returns the longest command named by the first arguments and the rest of them
+func findCommand(args []string) (*command, []string) {
+	var found *command
+	var words int
+	for i := range commands {
+		name := strings.Fields(commands[i].name)
+		if len(name) <= words || len(args) < len(name) {
+			continue
 		}
-		environment = env.Config{
-			Debug:              os.Getenv("DEBUG") == "true",
-			PostgresHost:       os.Getenv("POSTGRES_HOST"),
-			PostgresPort:       os.Getenv("POSTGRES_PORT"),
-			PostgresDB:         os.Getenv("POSTGRES_NAME"),
-			PostgresUser:       os.Getenv("POSTGRES_USER"),
-			PostgresPassword:   os.Getenv("POSTGRES_PASSWORD"),
-			PostgresSSLEnabled: os.Getenv("POSTGRES_SSL_ENABLED") == "true",
-			MinterBaseCoin:     os.Getenv("MINTER_BASE_COIN"),
-			NodeGrpc:           os.Getenv("NODE_GRPC"),
-			AddressChunkSize:   addressChunkSize,
-			CoinsChunkSize:     coinsChunkSize,
-			BalanceChunkSize:   balanceChunkSize,
-			StakeChunkSize:     stakeChunkSize,
-			ValidatorChunkSize: validatorChunkSize,
+		if strings.Join(args[:len(name)], " ") == commands[i].name {
+			found, words = &commands[i], len(name)
 		}
 	}
-
-	uploader := core.New(environment)
-	err := uploader.Do()
-	if err != nil {
-		panic(err)
+	if found == nil {
+		return nil, nil
 	}
+	return found, args[words:]
+}
 
-	os.Exit(0)
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", binary)
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -help' for flags of the command.\n", binary)
 }
@@ -1,8 +1,10 @@
 package core
 
 import (
+	"fmt"
 	"github.com/MinterTeam/explorer-genesis-uploader/domain"
 	"github.com/MinterTeam/node-grpc-gateway/api_pb"
+	"strconv"
 )
 
 func (egu *ExplorerGenesisUploader) convertResponseToModel(response *api_pb.GenesisResponse) *domain.Genesis {
@@ -38,6 +40,8 @@ func (egu *ExplorerGenesisUploader) convertResponseToModel(response *api_pb.Gene
 			key = &f.CandidateKey.Value
 		}
 
+		// the gateway does not return move_to_candidate_id, so stake moving
+		// to another candidate is stored as unbond from the source candidate
 		frozenFunds = append(frozenFunds, domain.FrozenFund{
 			Height:       f.Height,
 			Address:      f.Address,
@@ -48,6 +52,16 @@ func (egu *ExplorerGenesisUploader) convertResponseToModel(response *api_pb.Gene
 		})
 	}
 
+	var waitlist []domain.Waitlist
+	for _, w := range response.AppState.Waitlist {
+		waitlist = append(waitlist, domain.Waitlist{
+			Owner:       w.Owner,
+			Coin:        w.Coin,
+			Value:       w.Value,
+			CandidateID: strconv.FormatUint(w.CandidateId, 10),
+		})
+	}
+
 	appState := domain.AppState{
 		Version:             response.AppState.Version,
 		Note:                response.AppState.Note,
@@ -57,7 +71,7 @@ func (egu *ExplorerGenesisUploader) convertResponseToModel(response *api_pb.Gene
 		Coins:               coins,
 		FrozenFunds:         frozenFunds,
 		BlockListCandidates: nil, //TODO: unuseful for now
-		Waitlist:            nil, //TODO: unuseful for now
+		Waitlist:            waitlist,
 		Accounts:            egu.convertAccounts(response),
 		HaltBlocks:          nil, //TODO: unuseful for now
 		Pools:               egu.convertPools(response),
@@ -70,6 +84,7 @@ func (egu *ExplorerGenesisUploader) convertResponseToModel(response *api_pb.Gene
 	}
 
 	g.AppState = appState
+	g.ChainID = response.ChainId
 	g.InitialHeight = response.InitialHeight
 
 	return g
@@ -79,6 +94,7 @@ func (egu *ExplorerGenesisUploader) convertCandidates(response *api_pb.GenesisRe
 	var candidates []domain.Candidate
 	for _, c := range response.AppState.Candidates {
 		stakes := egu.convertCandidateStakes(c.Stakes)
+		updates := egu.convertCandidateStakes(c.Updates)
 		candidates = append(candidates, domain.Candidate{
 			ID:                       c.Id,
 			RewardAddress:            c.RewardAddress,
@@ -88,7 +104,7 @@ func (egu *ExplorerGenesisUploader) convertCandidates(response *api_pb.GenesisRe
 			PublicKey:                c.PublicKey,
 			Commission:               c.Commission,
 			Stakes:                   stakes,
-			Updates:                  nil, //TODO: unuseful for now
+			Updates:                  updates,
 			Status:                   c.Status,
 			JailedUntil:              c.JailedUntil,
 			LastEditCommissionHeight: c.LastEditCommissionHeight,
@@ -170,3 +186,190 @@ func (egu *ExplorerGenesisUploader) convertPools(response *api_pb.GenesisRespons
 
 	return list
 }
+
+func (egu *ExplorerGenesisUploader) convertFileToModel(gf *domain.GenesisFile) (*domain.Genesis, error) {
+	p := new(uintParser)
+
+	g := &domain.Genesis{
+		GenesisTime:   gf.GenesisTime,
+		ChainID:       gf.ChainID,
+		InitialHeight: p.parse("initial_height", gf.InitialHeight),
+		AppHash:       gf.AppHash,
+	}
+
+	var coins []domain.GenesisCoin
+	for _, c := range gf.AppState.Coins {
+		coins = append(coins, domain.GenesisCoin{
+			ID:           p.parse("coin.id", c.ID),
+			Name:         c.Name,
+			Symbol:       c.Symbol,
+			Volume:       c.Volume,
+			Crr:          p.parse("coin.crr", c.Crr),
+			Reserve:      c.Reserve,
+			MaxSupply:    c.MaxSupply,
+			Version:      p.parse("coin.version", c.Version),
+			OwnerAddress: c.OwnerAddress,
+			Mintable:     c.Mintable,
+			Burnable:     c.Burnable,
+		})
+	}
+
+	var frozenFunds []domain.FrozenFund
+	for _, f := range gf.AppState.FrozenFunds {
+		fund := domain.FrozenFund{
+			Height:       p.parse("frozen_fund.height", f.Height),
+			Address:      f.Address,
+			CandidateKey: f.CandidateKey,
+			CandidateID:  p.parse("frozen_fund.candidate_id", f.CandidateID),
+			Coin:         p.parse("frozen_fund.coin", f.Coin),
+			Value:        f.Value,
+		}
+		if f.MoveToCandidateID != nil {
+			moveTo := p.parse("frozen_fund.move_to_candidate_id", *f.MoveToCandidateID)
+			fund.MoveToCandidateID = &moveTo
+		}
+		frozenFunds = append(frozenFunds, fund)
+	}
+
+	var waitlist []domain.Waitlist
+	for _, w := range gf.AppState.Waitlist {
+		item := domain.Waitlist{
+			Owner: w.Owner,
+			Coin:  p.parse("waitlist.coin", w.Coin),
+			Value: w.Value,
+		}
+		if w.BipValue != nil {
+			item.BipValue = *w.BipValue
+		}
+		if w.CandidateID != nil {
+			item.CandidateID = *w.CandidateID
+		}
+		waitlist = append(waitlist, item)
+	}
+
+	convertStakes := func(field string, list []domain.GenesisFileWaitlist) []domain.GenesisStake {
+		var stakes []domain.GenesisStake
+		for _, s := range list {
+			stake := domain.GenesisStake{
+				Owner: s.Owner,
+				Coin:  p.parse(field, s.Coin),
+				Value: s.Value,
+			}
+			if s.BipValue != nil {
+				stake.BipValue = *s.BipValue
+			}
+			stakes = append(stakes, stake)
+		}
+		return stakes
+	}
+
+	var candidates []domain.Candidate
+	for _, c := range gf.AppState.Candidates {
+		stakes := convertStakes("candidate.stake.coin", c.Stakes)
+		updates := convertStakes("candidate.update.coin", c.Updates)
+		candidates = append(candidates, domain.Candidate{
+			ID:                       p.parse("candidate.id", c.ID),
+			RewardAddress:            c.RewardAddress,
+			OwnerAddress:             c.OwnerAddress,
+			ControlAddress:           c.ControlAddress,
+			TotalBipStake:            c.TotalBipStake,
+			PublicKey:                c.PublicKey,
+			Commission:               p.parse("candidate.commission", c.Commission),
+			Stakes:                   stakes,
+			Updates:                  updates,
+			Status:                   int64(p.parse("candidate.status", c.Status)),
+			JailedUntil:              int64(p.parse("candidate.jailed_until", c.JailedUntil)),
+			LastEditCommissionHeight: int64(p.parse("candidate.last_edit_commission_height", c.LastEditCommissionHeight)),
+		})
+	}
+
+	var accounts []domain.Account
+	for _, a := range gf.AppState.Accounts {
+		var msd *domain.MultisigData
+		if a.MultisigData != nil {
+			var weights []uint64
+			for _, w := range a.MultisigData.Weights {
+				weights = append(weights, p.parse("account.multisig_data.weights", w))
+			}
+			msd = &domain.MultisigData{
+				Threshold: p.parse("account.multisig_data.threshold", a.MultisigData.Threshold),
+				Weights:   weights,
+				Addresses: a.MultisigData.Addresses,
+			}
+		}
+
+		var balances []domain.GenesisBalance
+		for _, b := range a.Balance {
+			balances = append(balances, domain.GenesisBalance{
+				Coin:  p.parse("account.balance.coin", b.Coin),
+				Value: b.Value,
+			})
+		}
+
+		accounts = append(accounts, domain.Account{
+			Address:      a.Address,
+			Balance:      balances,
+			Nonce:        p.parse("account.nonce", a.Nonce),
+			MultisigData: msd,
+		})
+	}
+
+	var pools []domain.Pool
+	for _, pl := range gf.AppState.Pools {
+		var orders []domain.GenesisOrder
+		for _, o := range pl.Orders {
+			orders = append(orders, domain.GenesisOrder{
+				IsSale:  o.IsSale,
+				Volume0: o.Volume0,
+				Volume1: o.Volume1,
+				Id:      p.parse("pool.order.id", o.ID),
+				Owner:   o.Owner,
+				Height:  p.parse("pool.order.height", o.Height),
+			})
+		}
+
+		pools = append(pools, domain.Pool{
+			Coin0:    p.parse("pool.coin0", pl.Coin0),
+			Coin1:    p.parse("pool.coin1", pl.Coin1),
+			Reserve0: pl.Reserve0,
+			Reserve1: pl.Reserve1,
+			ID:       p.parse("pool.id", pl.ID),
+			Orders:   orders,
+		})
+	}
+
+	g.AppState = domain.AppState{
+		Version:      gf.AppState.Version,
+		Note:         gf.AppState.Note,
+		Candidates:   candidates,
+		Coins:        coins,
+		FrozenFunds:  frozenFunds,
+		Waitlist:     waitlist,
+		Accounts:     accounts,
+		Pools:        pools,
+		NextOrderID:  p.parse("next_order_id", gf.AppState.NextOrderID),
+		MaxGas:       p.parse("max_gas", gf.AppState.MaxGas),
+		TotalSlashed: gf.AppState.TotalSlashed,
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+	return g, nil
+}
+
+// uintParser parses numeric fields of genesis file keeping the first error
+type uintParser struct {
+	err error
+}
+
+func (p *uintParser) parse(field, value string) uint64 {
+	if value == "" || p.err != nil {
+		return 0
+	}
+	v, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		p.err = fmt.Errorf("genesis file: %s: %w", field, err)
+	}
+	return v
+}
@@ -0,0 +1,545 @@
+package core
+
+import (
+	"fmt"
+	"github.com/MinterTeam/explorer-genesis-uploader/domain"
+	"github.com/MinterTeam/explorer-genesis-uploader/helpers"
+	"io"
+	"math/big"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Kinds of a change
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Change is an entity which differs between two states
+type Change struct {
+	Entity string `json:"entity"`
+	Key    string `json:"key"`
+	Kind   string `json:"kind"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+// EntitySummary counts changes of one kind of entities
+type EntitySummary struct {
+	Entity    string `json:"entity"`
+	Added     int    `json:"added"`
+	Removed   int    `json:"removed"`
+	Changed   int    `json:"changed"`
+	Unchanged int    `json:"unchanged"`
+}
+
+// CoinTotal compares sums of balances and stakes of a coin
+type CoinTotal struct {
+	Symbol      string `json:"symbol"`
+	OldBalances string `json:"old_balances"`
+	NewBalances string `json:"new_balances"`
+	OldStakes   string `json:"old_stakes"`
+	NewStakes   string `json:"new_stakes"`
+}
+
+// Diff is a difference between an old state, e.g. explorer DB, and a new one, e.g. genesis
+type Diff struct {
+	Old      string          `json:"old"`
+	New      string          `json:"new"`
+	Entities []EntitySummary `json:"entities"`
+	Coins    []CoinTotal     `json:"coins"`
+	Changes  []Change        `json:"changes"`
+}
+
+// state is a chain state keyed by addresses, public keys and coin symbols, so genesis
+// and DB can be compared regardless of DB ids, as merge matches coins by symbol
+type state struct {
+	addresses  map[string]struct{}
+	coins      map[string]coinState
+	validators map[string]validatorState
+	balances   map[balanceKey]string
+	stakes     map[stakeKey]string
+	pools      map[uint64]poolState
+	orders     map[uint64]orderState
+}
+
+type coinState struct {
+	Volume    string
+	Reserve   string
+	Crr       uint64
+	MaxSupply string
+}
+
+type validatorState struct {
+	ID            uint64
+	OwnerAddress  string
+	RewardAddress string
+	Commission    uint64
+	Status        uint64
+	TotalStake    string
+}
+
+type poolState struct {
+	Coin0, Coin1       string
+	Reserve0, Reserve1 string
+}
+
+type orderState struct {
+	Pool       uint64
+	Owner      string
+	CoinSell   string
+	SellVolume string
+	CoinBuy    string
+	BuyVolume  string
+}
+
+type balanceKey struct {
+	address string
+	coin    string
+}
+
+type stakeKey struct {
+	validator string
+	owner     string
+	coin      string
+}
+
+// coinSymbols names coins of a state, a coin missing from the state is named by its id
+type coinSymbols map[uint64]string
+
+func (s coinSymbols) symbol(id uint64) string {
+	if symbol, ok := s[id]; ok {
+		return symbol
+	}
+	return fmt.Sprintf("#%d", id)
+}
+
+func newState() *state {
+	return &state{
+		addresses:  make(map[string]struct{}),
+		coins:      make(map[string]coinState),
+		validators: make(map[string]validatorState),
+		balances:   make(map[balanceKey]string),
+		stakes:     make(map[stakeKey]string),
+		pools:      make(map[uint64]poolState),
+		orders:     make(map[uint64]orderState),
+	}
+}
+
+// amount normalizes a number, so "0100" from one source equals "100" from another
+func amount(value string) string {
+	v, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return value
+	}
+	return v.String()
+}
+
+// stateFromGenesis builds state the uploader would produce from genesis, base coin excluded
+func stateFromGenesis(genesis *domain.Genesis, baseCoin string) *state {
+	s := newState()
+	s.addresses[zeroAddress] = struct{}{}
+
+	symbols := coinSymbols{baseCoinId: baseCoin}
+	for _, c := range genesis.AppState.Coins {
+		symbols[c.ID] = c.Symbol
+	}
+
+	for _, c := range genesis.AppState.Coins {
+		if c.ID == baseCoinId {
+			continue
+		}
+		s.coins[c.Symbol] = coinState{
+			Volume:    amount(c.Volume),
+			Reserve:   amount(c.Reserve),
+			Crr:       c.Crr,
+			MaxSupply: amount(c.MaxSupply),
+		}
+		if c.OwnerAddress != nil && *c.OwnerAddress != "" {
+			s.addresses[helpers.RemovePrefix(*c.OwnerAddress)] = struct{}{}
+		}
+	}
+
+	for _, account := range genesis.AppState.Accounts {
+		address := helpers.RemovePrefix(account.Address)
+		s.addresses[address] = struct{}{}
+		for _, b := range account.Balance {
+			s.balances[balanceKey{address, symbols.symbol(b.Coin)}] = amount(b.Value)
+		}
+	}
+
+	for _, c := range genesis.AppState.Candidates {
+		pk := helpers.RemovePrefix(c.PublicKey)
+		owner := helpers.RemovePrefix(c.OwnerAddress)
+		reward := helpers.RemovePrefix(c.RewardAddress)
+		s.addresses[owner] = struct{}{}
+		s.addresses[reward] = struct{}{}
+		s.validators[pk] = validatorState{
+			ID:            c.ID,
+			OwnerAddress:  owner,
+			RewardAddress: reward,
+			Commission:    c.Commission,
+			Status:        uint64(c.Status),
+			TotalStake:    amount(c.TotalBipStake),
+		}
+		for _, stake := range c.Stakes {
+			stakeOwner := helpers.RemovePrefix(stake.Owner)
+			s.addresses[stakeOwner] = struct{}{}
+			s.stakes[stakeKey{pk, stakeOwner, symbols.symbol(stake.Coin)}] = amount(stake.Value)
+		}
+	}
+
+	for _, f := range genesis.AppState.FrozenFunds {
+		s.addresses[helpers.RemovePrefix(f.Address)] = struct{}{}
+	}
+
+	for _, p := range genesis.AppState.Pools {
+		coin0, coin1 := symbols.symbol(p.Coin0), symbols.symbol(p.Coin1)
+		s.pools[p.ID] = poolState{
+			Coin0:    coin0,
+			Coin1:    coin1,
+			Reserve0: amount(p.Reserve0),
+			Reserve1: amount(p.Reserve1),
+		}
+		for _, o := range p.Orders {
+			order := orderState{Pool: p.ID, Owner: helpers.RemovePrefix(o.Owner)}
+			if o.IsSale {
+				order.CoinSell, order.SellVolume = coin0, amount(o.Volume0)
+				order.CoinBuy, order.BuyVolume = coin1, amount(o.Volume1)
+			} else {
+				order.CoinSell, order.SellVolume = coin1, amount(o.Volume1)
+				order.CoinBuy, order.BuyVolume = coin0, amount(o.Volume0)
+			}
+			s.orders[o.Id] = order
+		}
+	}
+
+	return s
+}
+
+// stateFromDB reads state stored in explorer DB, base coin excluded
+func (egu *ExplorerGenesisUploader) stateFromDB() (*state, error) {
+	s := newState()
+
+	addresses, err := egu.addressRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	addressById := make(map[uint64]string, len(addresses))
+	for _, a := range addresses {
+		addressById[a.ID] = a.Address
+		s.addresses[a.Address] = struct{}{}
+	}
+	address := func(id *uint64) string {
+		if id == nil {
+			return ""
+		}
+		return addressById[*id]
+	}
+
+	coins, err := egu.coinRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	symbols := coinSymbols{baseCoinId: egu.env.MinterBaseCoin}
+	for _, c := range coins {
+		symbols[uint64(c.ID)] = c.Symbol
+		if c.ID == baseCoinId {
+			continue
+		}
+		s.coins[c.Symbol] = coinState{
+			Volume:    amount(c.Volume),
+			Reserve:   amount(c.Reserve),
+			Crr:       uint64(c.Crr),
+			MaxSupply: amount(c.MaxSupply),
+		}
+	}
+
+	validators, err := egu.validatorRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	pkById := make(map[uint]string, len(validators))
+	for _, v := range validators {
+		pkById[v.ID] = v.PublicKey
+		vs := validatorState{
+			ID:            uint64(v.ID),
+			OwnerAddress:  address(v.OwnerAddressID),
+			RewardAddress: address(v.RewardAddressID),
+		}
+		if v.Commission != nil {
+			vs.Commission = *v.Commission
+		}
+		if v.Status != nil {
+			vs.Status = uint64(*v.Status)
+		}
+		if v.TotalStake != nil {
+			vs.TotalStake = amount(*v.TotalStake)
+		}
+		s.validators[v.PublicKey] = vs
+	}
+
+	balances, err := egu.balanceRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range balances {
+		s.balances[balanceKey{addressById[b.AddressID], symbols.symbol(b.CoinID)}] = amount(b.Value)
+	}
+
+	stakes, err := egu.validatorRepository.GetAllStakes()
+	if err != nil {
+		return nil, err
+	}
+	for _, stake := range stakes {
+		s.stakes[stakeKey{pkById[stake.ValidatorID], addressById[stake.OwnerAddressID], symbols.symbol(stake.CoinID)}] = amount(stake.Value)
+	}
+
+	pools, err := egu.liquidityPoolRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pools {
+		s.pools[p.Id] = poolState{
+			Coin0:    symbols.symbol(p.FirstCoinId),
+			Coin1:    symbols.symbol(p.SecondCoinId),
+			Reserve0: amount(p.FirstCoinVolume),
+			Reserve1: amount(p.SecondCoinVolume),
+		}
+	}
+
+	orders, err := egu.liquidityPoolRepository.GetAllOrders()
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range orders {
+		s.orders[o.Id] = orderState{
+			Pool:       o.LiquidityPoolId,
+			Owner:      addressById[o.AddressId],
+			CoinSell:   symbols.symbol(o.CoinSellId),
+			SellVolume: amount(o.CoinSellVolume),
+			CoinBuy:    symbols.symbol(o.CoinBuyId),
+			BuyVolume:  amount(o.CoinBuyVolume),
+		}
+	}
+
+	return s, nil
+}
+
+// entries returns state entities keyed for output, values must be comparable
+func (s *state) entries() map[string]map[string]interface{} {
+	e := map[string]map[string]interface{}{
+		"addresses":  make(map[string]interface{}, len(s.addresses)),
+		"coins":      make(map[string]interface{}, len(s.coins)),
+		"validators": make(map[string]interface{}, len(s.validators)),
+		"balances":   make(map[string]interface{}, len(s.balances)),
+		"stakes":     make(map[string]interface{}, len(s.stakes)),
+		"pools":      make(map[string]interface{}, len(s.pools)),
+		"orders":     make(map[string]interface{}, len(s.orders)),
+	}
+	for a := range s.addresses {
+		e["addresses"]["Mx"+a] = true
+	}
+	for symbol, c := range s.coins {
+		e["coins"][symbol] = c
+	}
+	for pk, v := range s.validators {
+		e["validators"]["Mp"+pk] = v
+	}
+	for k, v := range s.balances {
+		e["balances"][fmt.Sprintf("Mx%s/%s", k.address, k.coin)] = v
+	}
+	for k, v := range s.stakes {
+		e["stakes"][fmt.Sprintf("Mp%s/Mx%s/%s", k.validator, k.owner, k.coin)] = v
+	}
+	for id, p := range s.pools {
+		e["pools"][fmt.Sprint(id)] = p
+	}
+	for id, o := range s.orders {
+		e["orders"][fmt.Sprint(id)] = o
+	}
+	return e
+}
+
+// diffEntities is the order of entities in a diff
+var diffEntities = []string{"addresses", "coins", "validators", "balances", "stakes", "pools", "orders"}
+
+func formatEntry(v interface{}) string {
+	if v == true {
+		return ""
+	}
+	return fmt.Sprintf("%+v", v)
+}
+
+// diffStates compares states entity by entity and sums balances and stakes per coin
+func diffStates(before, after *state) *Diff {
+	diff := new(Diff)
+	beforeEntries, afterEntries := before.entries(), after.entries()
+
+	for _, entity := range diffEntities {
+		summary := EntitySummary{Entity: entity}
+		b, a := beforeEntries[entity], afterEntries[entity]
+
+		keys := make([]string, 0, len(a))
+		for key := range b {
+			keys = append(keys, key)
+		}
+		for key := range a {
+			if _, ok := b[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			old, inBefore := b[key]
+			now, inAfter := a[key]
+			change := Change{Entity: entity, Key: key}
+			switch {
+			case !inAfter:
+				summary.Removed++
+				change.Kind, change.Old = ChangeRemoved, formatEntry(old)
+			case !inBefore:
+				summary.Added++
+				change.Kind, change.New = ChangeAdded, formatEntry(now)
+			case old != now:
+				summary.Changed++
+				change.Kind, change.Old, change.New = ChangeChanged, formatEntry(old), formatEntry(now)
+			default:
+				summary.Unchanged++
+				continue
+			}
+			diff.Changes = append(diff.Changes, change)
+		}
+		diff.Entities = append(diff.Entities, summary)
+	}
+
+	diff.Coins = coinTotals(before, after)
+	return diff
+}
+
+func coinTotals(before, after *state) []CoinTotal {
+	type sums struct {
+		oldBalances, newBalances, oldStakes, newStakes big.Int
+	}
+	totals := make(map[string]*sums)
+	total := func(coin string) *sums {
+		if totals[coin] == nil {
+			totals[coin] = new(sums)
+		}
+		return totals[coin]
+	}
+	add := func(sum *big.Int, value string) {
+		if v, ok := new(big.Int).SetString(value, 10); ok {
+			sum.Add(sum, v)
+		}
+	}
+	for k, v := range before.balances {
+		add(&total(k.coin).oldBalances, v)
+	}
+	for k, v := range after.balances {
+		add(&total(k.coin).newBalances, v)
+	}
+	for k, v := range before.stakes {
+		add(&total(k.coin).oldStakes, v)
+	}
+	for k, v := range after.stakes {
+		add(&total(k.coin).newStakes, v)
+	}
+
+	coins := make([]string, 0, len(totals))
+	for coin := range totals {
+		coins = append(coins, coin)
+	}
+	sort.Strings(coins)
+
+	list := make([]CoinTotal, 0, len(coins))
+	for _, coin := range coins {
+		t := totals[coin]
+		list = append(list, CoinTotal{
+			Symbol:      coin,
+			OldBalances: t.oldBalances.String(),
+			NewBalances: t.newBalances.String(),
+			OldStakes:   t.oldStakes.String(),
+			NewStakes:   t.newStakes.String(),
+		})
+	}
+	return list
+}
+
+// Diff compares explorer DB with genesis, DB is the old state and genesis the new one
+func (egu *ExplorerGenesisUploader) Diff() (*Diff, error) {
+	start := time.Now()
+	egu.logger.Info("Getting genesis data...")
+	genesis, err := egu.loadGenesis()
+	if err != nil {
+		egu.logger.Error(err)
+		return nil, err
+	}
+
+	egu.logger.Info("Reading explorer DB...")
+	stored, err := egu.stateFromDB()
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrDBUnavailable, err)
+		egu.logger.Error(err)
+		return nil, err
+	}
+
+	diff := diffStates(stored, stateFromGenesis(genesis, egu.env.MinterBaseCoin))
+	diff.Old = "db:" + egu.env.PostgresDB
+	diff.New = egu.source()
+	egu.logger.Info(fmt.Sprintf("Diff complete, %d changes found. Processing time %s", len(diff.Changes), time.Since(start)))
+	return diff, nil
+}
+
+// DiffGenesis compares two genesis sources, each is "file:<path>" or "grpc:<address>"
+func (egu *ExplorerGenesisUploader) DiffGenesis(oldSource, newSource string) (*Diff, error) {
+	start := time.Now()
+	states := make([]*state, 2)
+	for i, source := range []string{oldSource, newSource} {
+		egu.logger.Info(fmt.Sprintf("Getting genesis data from %s...", source))
+		genesis, err := egu.loadGenesisFrom(source)
+		if err != nil {
+			egu.logger.Error(err)
+			return nil, err
+		}
+		states[i] = stateFromGenesis(genesis, egu.env.MinterBaseCoin)
+	}
+
+	diff := diffStates(states[0], states[1])
+	diff.Old, diff.New = oldSource, newSource
+	egu.logger.Info(fmt.Sprintf("Diff complete, %d changes found. Processing time %s", len(diff.Changes), time.Since(start)))
+	return diff, nil
+}
+
+// WriteSummary writes a readable summary of the diff listing at most limit changes
+func (d *Diff) WriteSummary(w io.Writer, limit int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Old: %s\nNew: %s\n\n", d.Old, d.New)
+
+	fmt.Fprintln(tw, "ENTITY\tADDED\tREMOVED\tCHANGED\tUNCHANGED")
+	for _, e := range d.Entities {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\n", e.Entity, e.Added, e.Removed, e.Changed, e.Unchanged)
+	}
+
+	fmt.Fprintln(tw, "\nCOIN\tOLD BALANCES\tNEW BALANCES\tOLD STAKES\tNEW STAKES")
+	for _, c := range d.Coins {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", c.Symbol, c.OldBalances, c.NewBalances, c.OldStakes, c.NewStakes)
+	}
+
+	if len(d.Changes) > 0 {
+		shown := d.Changes
+		if limit >= 0 && len(shown) > limit {
+			shown = shown[:limit]
+		}
+		fmt.Fprintf(tw, "\nCHANGES (%d of %d)\n", len(shown), len(d.Changes))
+		for _, c := range shown {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", c.Kind, c.Entity, c.Key, c.Old, c.New)
+		}
+	}
+
+	return tw.Flush()
+}
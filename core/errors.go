@@ -0,0 +1,13 @@
+package core
+
+import "errors"
+
+// Causes of a failed run, use errors.Is to tell them apart
+var (
+	ErrSourceUnavailable = errors.New("genesis source unavailable")
+	ErrDBUnavailable     = errors.New("DB unavailable")
+	ErrDBNotEmpty        = errors.New("DB is not empty")
+	ErrValidationFailed  = errors.New("genesis validation failed")
+	ErrDBWrite           = errors.New("DB write failed")
+	ErrWipeRefused       = errors.New("wipe refused")
+)
@@ -0,0 +1,231 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MinterTeam/explorer-genesis-uploader/domain"
+	"github.com/go-pg/pg/v10"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Export reconstructs genesis file from explorer DB, so it can be uploaded again or compared
+// with the original one. Data the explorer does not keep, e.g. nonces, multisig data,
+// control addresses, waitlist and candidates stake moves to, is left empty.
+func (egu *ExplorerGenesisUploader) Export() (*domain.GenesisFile, error) {
+	start := time.Now()
+	egu.logger.Info("Reading explorer DB...")
+	gf, err := egu.genesisFromDB()
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrDBUnavailable, err)
+		egu.logger.Error(err)
+		return nil, err
+	}
+	egu.logger.Info(fmt.Sprintf("Genesis has been exported. Processing time %s", time.Since(start)))
+	return gf, nil
+}
+
+func (egu *ExplorerGenesisUploader) genesisFromDB() (*domain.GenesisFile, error) {
+	gf := &domain.GenesisFile{InitialHeight: "0"}
+
+	upload, err := egu.genesisUploadRepository.GetLast()
+	switch {
+	case err == nil:
+		gf.ChainID = upload.ChainID
+		gf.InitialHeight = formatUint(upload.InitialHeight)
+	case errors.Is(err, pg.ErrNoRows):
+		egu.logger.Warn("No upload recorded, chain id and initial height are left empty")
+	default:
+		return nil, err
+	}
+
+	addresses, err := egu.addressRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	addressById := make(map[uint64]string, len(addresses))
+	for _, a := range addresses {
+		addressById[a.ID] = "Mx" + a.Address
+	}
+
+	coins, err := egu.coinRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range coins {
+		if c.ID == baseCoinId {
+			continue
+		}
+		coin := domain.GenesisFileCoin{
+			ID:        formatUint(uint64(c.ID)),
+			Name:      c.Name,
+			Symbol:    c.Symbol,
+			Volume:    c.Volume,
+			Crr:       formatUint(uint64(c.Crr)),
+			Reserve:   c.Reserve,
+			MaxSupply: c.MaxSupply,
+			Version:   formatUint(uint64(c.Version)),
+		}
+		if owner, ok := addressById[uint64(c.OwnerAddressId)]; ok && c.OwnerAddressId != 0 {
+			coin.OwnerAddress = &owner
+		}
+		gf.AppState.Coins = append(gf.AppState.Coins, coin)
+	}
+
+	balances, err := egu.balanceRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		if balances[i].AddressID != balances[j].AddressID {
+			return balances[i].AddressID < balances[j].AddressID
+		}
+		return balances[i].CoinID < balances[j].CoinID
+	})
+	for i, b := range balances {
+		if i == 0 || balances[i-1].AddressID != b.AddressID {
+			gf.AppState.Accounts = append(gf.AppState.Accounts, domain.GenesisFileAccount{
+				Address: addressById[b.AddressID],
+				Nonce:   "0",
+			})
+		}
+		account := &gf.AppState.Accounts[len(gf.AppState.Accounts)-1]
+		account.Balance = append(account.Balance, domain.GenesisFileBalance{
+			Coin:  formatUint(b.CoinID),
+			Value: b.Value,
+		})
+	}
+
+	validators, err := egu.validatorRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	stakes, err := egu.validatorRepository.GetAllStakes()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(stakes, func(i, j int) bool {
+		return stakes[i].ID < stakes[j].ID
+	})
+	stakesByValidator := make(map[uint][]domain.GenesisFileWaitlist)
+	for _, s := range stakes {
+		bipValue := s.BipValue
+		stakesByValidator[s.ValidatorID] = append(stakesByValidator[s.ValidatorID], domain.GenesisFileWaitlist{
+			Owner:    addressById[s.OwnerAddressID],
+			Coin:     formatUint(s.CoinID),
+			Value:    s.Value,
+			BipValue: &bipValue,
+		})
+	}
+
+	pkById := make(map[uint]string, len(validators))
+	for _, v := range validators {
+		pkById[v.ID] = "Mp" + v.PublicKey
+		candidate := domain.GenesisFileCandidate{
+			ID:                       formatUint(uint64(v.ID)),
+			PublicKey:                "Mp" + v.PublicKey,
+			Commission:               "0",
+			Status:                   "0",
+			TotalBipStake:            "0",
+			JailedUntil:              "0",
+			LastEditCommissionHeight: "0",
+			Stakes:                   stakesByValidator[v.ID],
+		}
+		if v.OwnerAddressID != nil {
+			candidate.OwnerAddress = addressById[*v.OwnerAddressID]
+		}
+		if v.RewardAddressID != nil {
+			candidate.RewardAddress = addressById[*v.RewardAddressID]
+		}
+		if v.Commission != nil {
+			candidate.Commission = formatUint(*v.Commission)
+		}
+		if v.Status != nil {
+			candidate.Status = formatUint(uint64(*v.Status))
+		}
+		if v.TotalStake != nil {
+			candidate.TotalBipStake = *v.TotalStake
+		}
+		gf.AppState.Candidates = append(gf.AppState.Candidates, candidate)
+	}
+
+	unbonds, err := egu.validatorRepository.GetAllUnbonds()
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range unbonds {
+		fund := domain.GenesisFileFrozenFund{
+			Height:      formatUint(uint64(u.UnlockBlockId)),
+			Address:     addressById[uint64(u.AddressId)],
+			CandidateID: "0",
+			Coin:        formatUint(uint64(u.CoinId)),
+			Value:       u.Value,
+		}
+		if u.ValidatorId != nil {
+			fund.CandidateID = formatUint(uint64(*u.ValidatorId))
+			if pk, ok := pkById[*u.ValidatorId]; ok && u.Type != domain.UnbondTypeLock {
+				fund.CandidateKey = &pk
+			}
+		}
+		gf.AppState.FrozenFunds = append(gf.AppState.FrozenFunds, fund)
+	}
+
+	pools, err := egu.liquidityPoolRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	orders, err := egu.liquidityPoolRepository.GetAllOrders()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].Id < orders[j].Id
+	})
+	poolById := make(map[uint64]*domain.LiquidityPool, len(pools))
+	for _, p := range pools {
+		poolById[p.Id] = p
+	}
+	ordersByPool := make(map[uint64][]domain.GenesisFileOrder)
+	var nextOrderId uint64
+	for _, o := range orders {
+		pool, ok := poolById[o.LiquidityPoolId]
+		if !ok {
+			continue
+		}
+		order := domain.GenesisFileOrder{
+			IsSale: o.CoinSellId == pool.FirstCoinId,
+			ID:     formatUint(o.Id),
+			Owner:  addressById[o.AddressId],
+			Height: formatUint(o.CreatedAtBlock),
+		}
+		if order.IsSale {
+			order.Volume0, order.Volume1 = o.CoinSellVolume, o.CoinBuyVolume
+		} else {
+			order.Volume0, order.Volume1 = o.CoinBuyVolume, o.CoinSellVolume
+		}
+		ordersByPool[pool.Id] = append(ordersByPool[pool.Id], order)
+		if o.Id >= nextOrderId {
+			nextOrderId = o.Id + 1
+		}
+	}
+	for _, p := range pools {
+		gf.AppState.Pools = append(gf.AppState.Pools, domain.GenesisFilePool{
+			Coin0:    formatUint(p.FirstCoinId),
+			Coin1:    formatUint(p.SecondCoinId),
+			Reserve0: p.FirstCoinVolume,
+			Reserve1: p.SecondCoinVolume,
+			ID:       formatUint(p.Id),
+			Orders:   ordersByPool[p.Id],
+		})
+	}
+
+	gf.AppState.NextOrderID = formatUint(nextOrderId)
+	gf.AppState.MaxGas = "0"
+	gf.AppState.TotalSlashed = "0"
+	return gf, nil
+}
+
+func formatUint(v uint64) string {
+	return strconv.FormatUint(v, 10)
+}
@@ -0,0 +1,211 @@
+package core
+
+import (
+	"fmt"
+	"github.com/MinterTeam/explorer-genesis-uploader/domain"
+	"strings"
+	"time"
+)
+
+// extraction holds rows of every stage as an upload into an empty DB inserts them
+type extraction struct {
+	addresses             []*domain.Address
+	coins                 []*domain.Coin
+	validators            []*domain.Validator
+	validatorPublicKeys   []*domain.ValidatorPublicKeys
+	balances              []*domain.Balance
+	stakes                []*domain.Stake
+	unbonds               []*domain.Unbond
+	liquidityPools        []*domain.LiquidityPool
+	addressLiquidityPools []*domain.AddressLiquidityPool
+	orders                []domain.Order
+}
+
+// stageRows is a stage with its rows, rows is a slice of domain structs. Ids of sequenced
+// rows are generated by the table sequence on upload and assigned by extraction without DB.
+type stageRows struct {
+	stage     string
+	rows      interface{}
+	sequenced bool
+}
+
+// stages returns rows of every stage in the order of the upload
+func (e *extraction) stages() []stageRows {
+	return []stageRows{
+		{"addresses", e.addresses, true},
+		{"coins", e.coins, false},
+		{"validators", e.validators, false},
+		{"balances", e.balances, false},
+		{"stakes", e.stakes, true},
+		{"unbonds", e.unbonds, false},
+		{"liquidity_pools", e.liquidityPools, false},
+		{"address_liquidity_pools", e.addressLiquidityPools, false},
+		{"orders", e.orders, false},
+	}
+}
+
+// tables returns rows of every table the upload inserts into in the order of the upload
+func (e *extraction) tables() []stageRows {
+	stages := e.stages()
+	tables := append([]stageRows{}, stages[:3]...)
+	tables = append(tables, stageRows{"validator_public_keys", e.validatorPublicKeys, true})
+	return append(tables, stages[3:]...)
+}
+
+// stage is a step of the upload, extract returns the number of extracted rows
+type stage struct {
+	name    string
+	extract func() (int, error)
+	save    func() error
+}
+
+// extractAll runs every extract stage without DB, ids DB would generate are assigned
+// the way an upload into an empty DB gets them
+func (egu *ExplorerGenesisUploader) extractAll(genesis *domain.Genesis) (*extraction, error) {
+	return egu.runStages(genesis, false)
+}
+
+// runStages runs stages in the order of the upload. With save every stage saves its rows
+// before the next one is extracted, as later stages resolve ids DB has given to earlier ones.
+func (egu *ExplorerGenesisUploader) runStages(genesis *domain.Genesis, save bool) (*extraction, error) {
+	e := new(extraction)
+	var addresses []string
+	merge := save && egu.env.Merge
+
+	stages := []stage{
+		{
+			name: "addresses",
+			extract: func() (n int, err error) {
+				addresses, err = egu.extractAddresses(genesis)
+				if err == nil && !save {
+					e.addresses = egu.addressRepository.Assign(addresses)
+				}
+				return len(addresses), err
+			},
+			save: func() error { return egu.saveAddresses(addresses) },
+		},
+		{
+			name: "coins",
+			extract: func() (n int, err error) {
+				e.coins, err = egu.extractCoins(genesis)
+				if err == nil && merge {
+					err = egu.matchCoins(e.coins)
+				}
+				return len(e.coins), err
+			},
+			save: func() error { return egu.saveCoins(e.coins) },
+		},
+		{
+			name: "validators",
+			extract: func() (n int, err error) {
+				e.validators, err = egu.extractCandidates(genesis)
+				if err == nil && merge {
+					err = egu.matchValidators(e.validators)
+				}
+				if err == nil && !save {
+					egu.validatorRepository.CachePks(e.validators)
+					for i, v := range e.validators {
+						e.validatorPublicKeys = append(e.validatorPublicKeys, &domain.ValidatorPublicKeys{
+							ID:          uint(i + 1),
+							ValidatorId: v.ID,
+							Key:         v.PublicKey,
+						})
+					}
+				}
+				return len(e.validators), err
+			},
+			save: func() error { return egu.saveCandidates(e.validators) },
+		},
+		{
+			name: "balances",
+			extract: func() (n int, err error) {
+				e.balances, err = egu.extractBalances(genesis)
+				return len(e.balances), err
+			},
+			save: func() error { return egu.saveBalances(e.balances) },
+		},
+		{
+			name: "stakes",
+			extract: func() (n int, err error) {
+				e.stakes, err = egu.extractStakes(genesis)
+				if !save {
+					for i, stake := range e.stakes {
+						stake.ID = uint(i + 1)
+					}
+				}
+				return len(e.stakes), err
+			},
+			save: func() error { return egu.saveStakes(e.stakes) },
+		},
+		{
+			name: "unbonds",
+			extract: func() (n int, err error) {
+				e.unbonds, err = egu.extractUnbonds(genesis)
+				return len(e.unbonds), err
+			},
+			save: func() error { return egu.saveUnbonds(e.unbonds) },
+		},
+		{
+			name: "liquidity_pools",
+			extract: func() (n int, err error) {
+				e.liquidityPools, err = egu.extractLiquidityPool(genesis, e.coins)
+				if err == nil && merge {
+					err = egu.matchPools(e.liquidityPools)
+				}
+				return len(e.liquidityPools), err
+			},
+			save: func() error { return egu.saveLiquidityPool(e.liquidityPools) },
+		},
+		{
+			name: "address_liquidity_pools",
+			extract: func() (n int, err error) {
+				e.addressLiquidityPools, err = egu.extractAddressLiquidityPools(genesis, e.liquidityPools)
+				return len(e.addressLiquidityPools), err
+			},
+			save: func() error { return egu.saveAddressLiquidityPools(e.addressLiquidityPools) },
+		},
+		{
+			name: "orders",
+			extract: func() (n int, err error) {
+				e.orders, err = egu.extractOrders(genesis, e.liquidityPools)
+				if err == nil && merge {
+					err = egu.checkOrders(e.orders)
+				}
+				return len(e.orders), err
+			},
+			save: func() error { return egu.saveOrders(e.orders) },
+		},
+	}
+
+	for _, s := range stages {
+		if err := egu.runStage(s, save); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// runStage extracts rows of the stage and saves them if save is set
+func (egu *ExplorerGenesisUploader) runStage(s stage, save bool) error {
+	title := strings.ReplaceAll(s.name, "_", " ")
+
+	egu.logger.Info(fmt.Sprintf("Extracting %s...", title))
+	start := time.Now()
+	rows, err := s.extract()
+	if err != nil {
+		return err
+	}
+	egu.logger.Info(fmt.Sprintf("%d %s have been extracted. Processing time %s", rows, title, time.Since(start)))
+	egu.extracted(s.name, rows, time.Since(start))
+	if !save {
+		return nil
+	}
+
+	start = time.Now()
+	if err := s.save(); err != nil {
+		return err
+	}
+	egu.savedIn(s.name, time.Since(start))
+	egu.logger.Info(fmt.Sprintf("%d %s have been saved. Processing time %s", rows, title, time.Since(start)))
+	return nil
+}
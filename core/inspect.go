@@ -0,0 +1,316 @@
+package core
+
+import (
+	"fmt"
+	"github.com/MinterTeam/explorer-genesis-uploader/domain"
+	"io"
+	"math/big"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// EntityCount is a number of entities of a kind in genesis
+type EntityCount struct {
+	Entity string `json:"entity"`
+	Count  int    `json:"count"`
+}
+
+// CoinSupply is a coin volume split by holders
+type CoinSupply struct {
+	Coin        uint64 `json:"coin"`
+	Symbol      string `json:"symbol"`
+	Volume      string `json:"volume"`
+	Holders     int    `json:"holders"`
+	Accounts    string `json:"accounts"`
+	Stakes      string `json:"stakes"`
+	Waitlist    string `json:"waitlist"`
+	FrozenFunds string `json:"frozen_funds"`
+	Pools       string `json:"pools"`
+	Orders      string `json:"orders"`
+}
+
+// Holder is an account balance of a coin
+type Holder struct {
+	Address string `json:"address"`
+	Value   string `json:"value"`
+}
+
+// CoinHolders is a coin with accounts holding most of it
+type CoinHolders struct {
+	Coin    uint64   `json:"coin"`
+	Symbol  string   `json:"symbol"`
+	Holders []Holder `json:"holders"`
+}
+
+// ValidatorStake is a candidate with its total stake
+type ValidatorStake struct {
+	ID         uint64 `json:"id"`
+	PublicKey  string `json:"public_key"`
+	Status     int64  `json:"status"`
+	TotalStake string `json:"total_stake"`
+	Stakes     int    `json:"stakes"`
+}
+
+// PoolOrders is a pool with the number of its orders
+type PoolOrders struct {
+	ID     uint64 `json:"id"`
+	Coin0  uint64 `json:"coin0"`
+	Coin1  uint64 `json:"coin1"`
+	Orders int    `json:"orders"`
+}
+
+// Inspection is statistics of genesis
+type Inspection struct {
+	Source        string           `json:"source"`
+	ChainID       string           `json:"chain_id"`
+	InitialHeight uint64           `json:"initial_height"`
+	Counts        []EntityCount    `json:"counts"`
+	Multisig      int              `json:"multisig_accounts"`
+	Supply        []CoinSupply     `json:"supply"`
+	TopHolders    []CoinHolders    `json:"top_holders"`
+	TopValidators []ValidatorStake `json:"top_validators"`
+	TopPools      []PoolOrders     `json:"top_pools"`
+}
+
+// Inspect computes statistics of genesis without DB, top limits lists of holders, validators and pools
+func (egu *ExplorerGenesisUploader) Inspect(top int) (*Inspection, error) {
+	start := time.Now()
+	egu.logger.Info("Getting genesis data...")
+	genesis, err := egu.loadGenesis()
+	if err != nil {
+		egu.logger.Error(err)
+		return nil, err
+	}
+
+	inspection, err := egu.inspect(genesis, top)
+	if err != nil {
+		egu.logger.Error(err)
+		return nil, err
+	}
+	inspection.Source = egu.source()
+	egu.logger.Info(fmt.Sprintf("Genesis has been inspected. Processing time %s", time.Since(start)))
+	return inspection, nil
+}
+
+func (egu *ExplorerGenesisUploader) inspect(genesis *domain.Genesis, top int) (*Inspection, error) {
+	addresses, err := egu.extractAddresses(genesis)
+	if err != nil {
+		return nil, err
+	}
+
+	in := &Inspection{
+		ChainID:       genesis.ChainID,
+		InitialHeight: genesis.InitialHeight,
+	}
+
+	symbols := map[uint64]string{baseCoinId: egu.env.MinterBaseCoin}
+	volumes := make(map[uint64]string)
+	for _, c := range genesis.AppState.Coins {
+		symbols[c.ID] = c.Symbol
+		volumes[c.ID] = amount(c.Volume)
+	}
+
+	supplies := make(map[uint64]*supplySums)
+	get := func(coin uint64) *supplySums {
+		s, ok := supplies[coin]
+		if !ok {
+			s = newSupplySums()
+			supplies[coin] = s
+		}
+		return s
+	}
+
+	holders := make(map[uint64][]Holder)
+	var balances, stakes, orders int
+	for _, account := range genesis.AppState.Accounts {
+		if account.MultisigData != nil {
+			in.Multisig++
+		}
+		for _, b := range account.Balance {
+			balances++
+			s := get(b.Coin)
+			s.holders++
+			addTo(s.accounts, b.Value)
+			holders[b.Coin] = append(holders[b.Coin], Holder{Address: account.Address, Value: amount(b.Value)})
+		}
+	}
+
+	for _, candidate := range genesis.AppState.Candidates {
+		stakes += len(candidate.Stakes)
+		for _, stake := range candidate.Stakes {
+			addTo(get(stake.Coin).stakes, stake.Value)
+		}
+		in.TopValidators = append(in.TopValidators, ValidatorStake{
+			ID:         candidate.ID,
+			PublicKey:  candidate.PublicKey,
+			Status:     candidate.Status,
+			TotalStake: amount(candidate.TotalBipStake),
+			Stakes:     len(candidate.Stakes),
+		})
+	}
+	for _, w := range genesis.AppState.Waitlist {
+		addTo(get(w.Coin).waitlist, w.Value)
+	}
+	for _, f := range genesis.AppState.FrozenFunds {
+		addTo(get(f.Coin).frozenFunds, f.Value)
+	}
+
+	for _, p := range genesis.AppState.Pools {
+		orders += len(p.Orders)
+		addTo(get(p.Coin0).pools, p.Reserve0)
+		addTo(get(p.Coin1).pools, p.Reserve1)
+		for _, o := range p.Orders {
+			if o.IsSale {
+				addTo(get(p.Coin0).orders, o.Volume0)
+			} else {
+				addTo(get(p.Coin1).orders, o.Volume1)
+			}
+		}
+		if len(p.Orders) > 0 {
+			in.TopPools = append(in.TopPools, PoolOrders{ID: p.ID, Coin0: p.Coin0, Coin1: p.Coin1, Orders: len(p.Orders)})
+		}
+	}
+
+	in.Counts = []EntityCount{
+		{"addresses", len(addresses)},
+		{"accounts", len(genesis.AppState.Accounts)},
+		{"balances", balances},
+		{"coins", len(genesis.AppState.Coins)},
+		{"candidates", len(genesis.AppState.Candidates)},
+		{"stakes", stakes},
+		{"waitlist", len(genesis.AppState.Waitlist)},
+		{"frozen_funds", len(genesis.AppState.FrozenFunds)},
+		{"pools", len(genesis.AppState.Pools)},
+		{"orders", orders},
+	}
+
+	coins := make([]uint64, 0, len(supplies))
+	for coin := range supplies {
+		coins = append(coins, coin)
+	}
+	sort.Slice(coins, func(i, j int) bool { return coins[i] < coins[j] })
+	for _, coin := range coins {
+		s := supplies[coin]
+		in.Supply = append(in.Supply, CoinSupply{
+			Coin:        coin,
+			Symbol:      symbols[coin],
+			Volume:      volumes[coin],
+			Holders:     s.holders,
+			Accounts:    s.accounts.String(),
+			Stakes:      s.stakes.String(),
+			Waitlist:    s.waitlist.String(),
+			FrozenFunds: s.frozenFunds.String(),
+			Pools:       s.pools.String(),
+			Orders:      s.orders.String(),
+		})
+
+		list := holders[coin]
+		if len(list) == 0 {
+			continue
+		}
+		sort.SliceStable(list, func(i, j int) bool { return compareAmounts(list[i].Value, list[j].Value) > 0 })
+		in.TopHolders = append(in.TopHolders, CoinHolders{Coin: coin, Symbol: symbols[coin], Holders: limitTop(list, top)})
+	}
+
+	sort.SliceStable(in.TopValidators, func(i, j int) bool {
+		return compareAmounts(in.TopValidators[i].TotalStake, in.TopValidators[j].TotalStake) > 0
+	})
+	if top >= 0 && len(in.TopValidators) > top {
+		in.TopValidators = in.TopValidators[:top]
+	}
+	sort.SliceStable(in.TopPools, func(i, j int) bool { return in.TopPools[i].Orders > in.TopPools[j].Orders })
+	if top >= 0 && len(in.TopPools) > top {
+		in.TopPools = in.TopPools[:top]
+	}
+
+	return in, nil
+}
+
+// WriteSummary writes inspection as text tables
+func (in *Inspection) WriteSummary(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Source: %s\nChain id: %s\nInitial height: %d\n\n", in.Source, in.ChainID, in.InitialHeight)
+
+	fmt.Fprintln(tw, "ENTITY\tCOUNT")
+	for _, c := range in.Counts {
+		fmt.Fprintf(tw, "%s\t%d\n", c.Entity, c.Count)
+	}
+	fmt.Fprintf(tw, "multisig accounts\t%d\n", in.Multisig)
+
+	fmt.Fprintln(tw, "\nCOIN\tSYMBOL\tVOLUME\tHOLDERS\tACCOUNTS\tSTAKES\tWAITLIST\tFROZEN FUNDS\tPOOLS\tORDERS")
+	for _, s := range in.Supply {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.Coin, s.Symbol, s.Volume, s.Holders, s.Accounts, s.Stakes, s.Waitlist, s.FrozenFunds, s.Pools, s.Orders)
+	}
+
+	fmt.Fprintln(tw, "\nVALIDATOR\tPUBLIC KEY\tSTATUS\tTOTAL STAKE\tSTAKES")
+	for _, v := range in.TopValidators {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%s\t%d\n", v.ID, v.PublicKey, v.Status, v.TotalStake, v.Stakes)
+	}
+
+	fmt.Fprintln(tw, "\nPOOL\tCOIN0\tCOIN1\tORDERS")
+	for _, p := range in.TopPools {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%d\n", p.ID, p.Coin0, p.Coin1, p.Orders)
+	}
+
+	fmt.Fprintln(tw, "\nCOIN\tSYMBOL\tHOLDER\tVALUE")
+	for _, c := range in.TopHolders {
+		for _, h := range c.Holders {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", c.Coin, c.Symbol, h.Address, h.Value)
+		}
+	}
+
+	return tw.Flush()
+}
+
+// supplySums is a coin volume split by holders
+type supplySums struct {
+	holders     int
+	accounts    *big.Int
+	stakes      *big.Int
+	waitlist    *big.Int
+	frozenFunds *big.Int
+	pools       *big.Int
+	orders      *big.Int
+}
+
+func newSupplySums() *supplySums {
+	return &supplySums{
+		accounts:    new(big.Int),
+		stakes:      new(big.Int),
+		waitlist:    new(big.Int),
+		frozenFunds: new(big.Int),
+		pools:       new(big.Int),
+		orders:      new(big.Int),
+	}
+}
+
+// addTo adds value to sum, values which are not numbers are ignored, validation reports them
+func addTo(sum *big.Int, value string) {
+	if v, ok := new(big.Int).SetString(value, 10); ok {
+		sum.Add(sum, v)
+	}
+}
+
+// compareAmounts compares amounts as numbers, values which are not numbers are the smallest
+func compareAmounts(a, b string) int {
+	x, okX := new(big.Int).SetString(a, 10)
+	y, okY := new(big.Int).SetString(b, 10)
+	switch {
+	case !okX && !okY:
+		return 0
+	case !okX:
+		return -1
+	case !okY:
+		return 1
+	}
+	return x.Cmp(y)
+}
+
+func limitTop(list []Holder, top int) []Holder {
+	if top >= 0 && len(list) > top {
+		return list[:top]
+	}
+	return list
+}
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"github.com/MinterTeam/explorer-genesis-uploader/domain"
+	"math/big"
+)
+
+const baseCoinId = 0
+
+// basePricer values coins in the base coin using genesis state only.
+// Coins paired with the base coin are priced by their deepest direct pool,
+// coins without such a pool but with crr > 0 by the bancor reserve formula,
+// all the rest by the shortest path through other pools, where among
+// paths of the same length the deepest pool wins.
+type basePricer struct {
+	prices map[uint64]*big.Rat
+}
+
+type pricedPool struct {
+	coin0, coin1       uint64
+	reserve0, reserve1 *big.Rat
+}
+
+func newBasePricer(genesis *domain.Genesis) *basePricer {
+	bp := &basePricer{prices: map[uint64]*big.Rat{baseCoinId: big.NewRat(1, 1)}}
+
+	var pools []pricedPool
+	for _, p := range genesis.AppState.Pools {
+		r0, ok0 := new(big.Rat).SetString(p.Reserve0)
+		r1, ok1 := new(big.Rat).SetString(p.Reserve1)
+		if !ok0 || !ok1 || r0.Sign() <= 0 || r1.Sign() <= 0 {
+			continue
+		}
+		pools = append(pools, pricedPool{coin0: p.Coin0, coin1: p.Coin1, reserve0: r0, reserve1: r1})
+	}
+
+	// direct pools and bancor reserves are one hop away from the base coin
+	next := bp.candidates(pools)
+	for _, c := range genesis.AppState.Coins {
+		if c.ID == baseCoinId || c.Crr == 0 {
+			continue
+		}
+		if _, ok := next[c.ID]; ok {
+			continue
+		}
+		volume, okV := new(big.Rat).SetString(c.Volume)
+		reserve, okR := new(big.Rat).SetString(c.Reserve)
+		if !okV || !okR || volume.Sign() <= 0 || reserve.Sign() <= 0 {
+			continue
+		}
+		// spot price of a bancor coin is reserve / (volume * crr)
+		price := new(big.Rat).Mul(volume, big.NewRat(int64(c.Crr), 100))
+		next[c.ID] = price.Quo(reserve, price)
+	}
+
+	for len(next) > 0 {
+		for id, price := range next {
+			bp.prices[id] = price
+		}
+		next = bp.candidates(pools)
+	}
+
+	return bp
+}
+
+// candidates returns prices of not yet priced coins that share a pool with
+// an already priced coin, choosing the pool with the largest base coin depth
+func (bp *basePricer) candidates(pools []pricedPool) map[uint64]*big.Rat {
+	prices := make(map[uint64]*big.Rat)
+	depth := make(map[uint64]*big.Rat)
+
+	offer := func(coin uint64, reserve *big.Rat, knownCoin uint64, knownReserve *big.Rat) {
+		if _, ok := bp.prices[coin]; ok {
+			return
+		}
+		knownPrice, ok := bp.prices[knownCoin]
+		if !ok {
+			return
+		}
+		d := new(big.Rat).Mul(knownReserve, knownPrice)
+		if best, ok := depth[coin]; ok && best.Cmp(d) >= 0 {
+			return
+		}
+		depth[coin] = d
+		prices[coin] = new(big.Rat).Quo(d, reserve)
+	}
+
+	for _, p := range pools {
+		offer(p.coin0, p.reserve0, p.coin1, p.reserve1)
+		offer(p.coin1, p.reserve1, p.coin0, p.reserve0)
+	}
+
+	return prices
+}
+
+// value returns amount of coin in the base coin, false if coin has no price
+func (bp *basePricer) value(coin uint64, amount string) (*big.Rat, bool) {
+	price, ok := bp.prices[coin]
+	if !ok {
+		return nil, false
+	}
+	a, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return nil, false
+	}
+	return a.Mul(a, price), true
+}
+
+// liquidityBip returns value of both pool reserves in the base coin rounded down to pip,
+// coins without a price are returned instead when a reserve cannot be valued
+func (bp *basePricer) liquidityBip(pool domain.Pool) (string, []uint64) {
+	v0, ok0 := bp.value(pool.Coin0, pool.Reserve0)
+	v1, ok1 := bp.value(pool.Coin1, pool.Reserve1)
+
+	var unpriced []uint64
+	if !ok0 {
+		unpriced = append(unpriced, pool.Coin0)
+	}
+	if !ok1 {
+		unpriced = append(unpriced, pool.Coin1)
+	}
+	if len(unpriced) > 0 {
+		return "", unpriced
+	}
+
+	total := new(big.Rat).Add(v0, v1)
+	return new(big.Int).Quo(total.Num(), total.Denom()).String(), nil
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"github.com/MinterTeam/explorer-genesis-uploader/domain"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+// pricedGenesis has coins priced every way basePricer knows
+func pricedGenesis() *domain.Genesis {
+	genesis := new(domain.Genesis)
+	genesis.AppState.Coins = []domain.GenesisCoin{
+		{ID: 1, Symbol: "DIRECT"},
+		{ID: 2, Symbol: "BANCOR", Crr: 50, Volume: "1000", Reserve: "500"},
+		{ID: 3, Symbol: "HOP"},
+		{ID: 4, Symbol: "ISLAND0"},
+		{ID: 5, Symbol: "ISLAND1"},
+		{ID: 6, Symbol: "BOTH", Crr: 100, Volume: "10", Reserve: "10"},
+		{ID: 7, Symbol: "THIRD"},
+		{ID: 8, Symbol: "EMPTY"},
+	}
+	genesis.AppState.Pools = []domain.Pool{
+		{ID: 1, Coin0: 0, Coin1: 1, Reserve0: "100", Reserve1: "50"},
+		{ID: 2, Coin0: 0, Coin1: 1, Reserve0: "10", Reserve1: "10"},
+		{ID: 3, Coin0: 1, Coin1: 3, Reserve0: "30", Reserve1: "20"},
+		{ID: 4, Coin0: 4, Coin1: 5, Reserve0: "10", Reserve1: "10"},
+		{ID: 5, Coin0: 0, Coin1: 6, Reserve0: "100", Reserve1: "25"},
+		{ID: 6, Coin0: 0, Coin1: 7, Reserve0: "10", Reserve1: "3"},
+		{ID: 7, Coin0: 7, Coin1: 1, Reserve0: "1", Reserve1: "1"},
+		{ID: 8, Coin0: 1, Coin1: 8, Reserve0: "10", Reserve1: "0"},
+	}
+	return genesis
+}
+
+func TestBasePricerPrices(t *testing.T) {
+	bp := newBasePricer(pricedGenesis())
+
+	tests := []struct {
+		name  string
+		coin  uint64
+		price string
+	}{
+		{"base coin", 0, "1/1"},
+		{"deepest direct pool", 1, "2/1"},
+		{"bancor reserve", 2, "1/1"},
+		{"path through another pool", 3, "3/1"},
+		{"direct pool before bancor reserve", 6, "4/1"},
+		{"fractional price", 7, "10/3"},
+		{"pools without priced coins", 4, ""},
+		{"empty reserve", 8, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, ok := bp.prices[tt.coin]
+			if tt.price == "" {
+				if ok {
+					t.Fatalf("coin %d has price %s, want none", tt.coin, price)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("coin %d has no price, want %s", tt.coin, tt.price)
+			}
+			if price.String() != tt.price {
+				t.Errorf("coin %d price = %s, want %s", tt.coin, price, tt.price)
+			}
+		})
+	}
+}
+
+func TestBasePricerLiquidityBip(t *testing.T) {
+	genesis := pricedGenesis()
+	bp := newBasePricer(genesis)
+
+	tests := []struct {
+		name     string
+		pool     int
+		value    string
+		unpriced []uint64
+	}{
+		{"base coin pool", 0, "200", nil},
+		{"path through another pool", 2, "120", nil},
+		{"rounded down", 6, "5", nil},
+		{"both coins unpriced", 3, "", []uint64{4, 5}},
+		{"one coin unpriced", 7, "", []uint64{8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, unpriced := bp.liquidityBip(genesis.AppState.Pools[tt.pool])
+			if value != tt.value {
+				t.Errorf("liquidityBip() value = %q, want %q", value, tt.value)
+			}
+			if !reflect.DeepEqual(unpriced, tt.unpriced) {
+				t.Errorf("liquidityBip() unpriced = %v, want %v", unpriced, tt.unpriced)
+			}
+		})
+	}
+}
+
+func TestBasePricerValue(t *testing.T) {
+	bp := newBasePricer(pricedGenesis())
+
+	if v, ok := bp.value(7, "3"); !ok || v.Cmp(big.NewRat(10, 1)) != 0 {
+		t.Errorf("value(7, 3) = %v, %v, want 10, true", v, ok)
+	}
+	if _, ok := bp.value(4, "3"); ok {
+		t.Error("value(4, 3) of unpriced coin is ok")
+	}
+	if _, ok := bp.value(1, "abc"); ok {
+		t.Error("value(1, abc) of malformed amount is ok")
+	}
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/MinterTeam/explorer-genesis-uploader/env"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+)
+
+// Version of the uploader, set at build time from the VERSION file:
+// go build -ldflags "-X github.com/MinterTeam/explorer-genesis-uploader/core.Version=$(cat VERSION)"
+var Version = "dev"
+
+// Log formats
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// newLogger builds logger from config, every line carries version and run id.
+// Invalid settings fall back to defaults with a warning. The returned log file
+// is nil unless logs are written to a file.
+func newLogger(cfg env.Config) (*logrus.Entry, io.Closer) {
+	logger := logrus.New()
+	logger.SetReportCaller(false)
+
+	var warnings []string
+	var logFile io.Closer
+
+	output, err := logOutput(cfg.LogOutput)
+	switch {
+	case err != nil:
+		warnings = append(warnings, err.Error())
+		output = os.Stdout
+	case output != os.Stdout && output != os.Stderr:
+		logFile = output.(io.Closer)
+	}
+	logger.SetOutput(output)
+
+	switch cfg.LogFormat {
+	case LogFormatJSON:
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	case "", LogFormatText:
+		logger.SetFormatter(&logrus.TextFormatter{
+			DisableColors: false,
+			FullTimestamp: true,
+		})
+	default:
+		warnings = append(warnings, fmt.Sprintf("unknown log format %q, using text", cfg.LogFormat))
+		logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	}
+
+	level := logrus.InfoLevel
+	if cfg.Debug {
+		level = logrus.DebugLevel
+	}
+	if cfg.LogLevel != "" {
+		l, err := logrus.ParseLevel(cfg.LogLevel)
+		if err != nil {
+			warnings = append(warnings, err.Error())
+		} else {
+			level = l
+		}
+	}
+	logger.SetLevel(level)
+
+	runID := cfg.RunID
+	if runID == "" {
+		runID = newRunID()
+	}
+
+	entry := logger.WithFields(logrus.Fields{
+		"version": Version,
+		"run_id":  runID,
+		"app":     "Minter Explorer Explorer Genesis Uploader",
+	})
+	for _, w := range warnings {
+		entry.Warn(w)
+	}
+	return entry, logFile
+}
+
+// logOutput opens log destination: stdout, stderr or a file to append to
+func logOutput(output string) (io.Writer, error) {
+	switch output {
+	case "", "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	default:
+		return os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	}
+}
+
+// newRunID returns a random id to tell logs of different runs apart
+func newRunID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
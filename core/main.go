@@ -4,34 +4,45 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"errors"
-	"flag"
 	"fmt"
 	"github.com/MinterTeam/explorer-genesis-uploader/domain"
 	"github.com/MinterTeam/explorer-genesis-uploader/env"
 	"github.com/MinterTeam/explorer-genesis-uploader/helpers"
 	"github.com/MinterTeam/explorer-genesis-uploader/repository"
+	"github.com/MinterTeam/explorer-genesis-uploader/validation"
 	"github.com/MinterTeam/minter-go-sdk/v2/api/grpc_client"
 	"github.com/go-pg/pg/v10"
 	"github.com/sirupsen/logrus"
+	"io"
 	"math"
-	"math/big"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
 
-//-file=./tmp/genesis.json
-var file = flag.String(`file`, "", `Path to genesis json file`)
-
 type ExplorerGenesisUploader struct {
 	startBlock              uint64
+	db                      *pg.DB
 	addressRepository       *repository.Address
 	balanceRepository       *repository.Balance
 	coinRepository          *repository.Coin
 	validatorRepository     *repository.Validator
 	liquidityPoolRepository *repository.LiquidityPool
+	schemaRepository        *repository.Schema
+	genesisUploadRepository *repository.GenesisUpload
 	logger                  *logrus.Entry
+	logFile                 io.Closer
 	env                     env.Config
+	report                  *Report
+	metrics                 *uploaderMetrics
+	progress                *progressTracker
+	// coinIds maps genesis coin ids to ids of the same coins in DB, set in merge mode
+	coinIds map[uint64]uint64
+	// validatorIds and poolIds map genesis ids the same way for validators and pools
+	validatorIds map[uint64]uint
+	poolIds      map[uint64]uint64
 }
 
 func (egu *ExplorerGenesisUploader) StartBlock() uint64 {
@@ -39,19 +50,6 @@ func (egu *ExplorerGenesisUploader) StartBlock() uint64 {
 }
 
 func New(cfg env.Config) *ExplorerGenesisUploader {
-	//Init Logger
-	logger := logrus.New()
-	logger.SetOutput(os.Stdout)
-	logger.SetReportCaller(false)
-	logger.SetFormatter(&logrus.TextFormatter{
-		DisableColors: false,
-		FullTimestamp: true,
-	})
-	contextLogger := logger.WithFields(logrus.Fields{
-		"version": "1.4.0",
-		"app":     "Minter Explorer Explorer Genesis Uploader",
-	})
-
 	pgOptions := &pg.Options{
 		Addr:     fmt.Sprintf("%s:%s", cfg.PostgresHost, cfg.PostgresPort),
 		User:     cfg.PostgresUser,
@@ -65,7 +63,18 @@ func New(cfg env.Config) *ExplorerGenesisUploader {
 		}
 	}
 
-	db := pg.Connect(pgOptions)
+	return newUploader(cfg, pg.Connect(pgOptions))
+}
+
+// NewOffline creates uploader which extracts genesis without DB, ids DB would generate
+// are assigned in insertion order of an empty DB
+func NewOffline(cfg env.Config) *ExplorerGenesisUploader {
+	return newUploader(cfg, nil)
+}
+
+func newUploader(cfg env.Config, db *pg.DB) *ExplorerGenesisUploader {
+	//Init Logger
+	contextLogger, logFile := newLogger(cfg)
 
 	// Repositories
 	addressRepository := repository.NewAddressRepository(db)
@@ -73,183 +82,245 @@ func New(cfg env.Config) *ExplorerGenesisUploader {
 	validatorRepository := repository.NewValidatorRepository(db)
 	balanceRepository := repository.NewBalanceRepository(db)
 	liquidityPoolRepository := repository.NewLiquidityPoolRepository(db)
+	schemaRepository := repository.NewSchemaRepository(db)
+	genesisUploadRepository := repository.NewGenesisUploadRepository(db)
+
+	if cfg.PoolTokenPrefix == "" {
+		cfg.PoolTokenPrefix = domain.DefaultPoolTokenPrefix
+	}
+	if cfg.ErrorPolicy == "" {
+		cfg.ErrorPolicy = PolicyLenient
+	}
 
 	return &ExplorerGenesisUploader{
 		env:                     cfg,
+		db:                      db,
 		addressRepository:       addressRepository,
 		balanceRepository:       balanceRepository,
 		coinRepository:          coinRepository,
 		validatorRepository:     validatorRepository,
 		liquidityPoolRepository: liquidityPoolRepository,
+		schemaRepository:        schemaRepository,
+		genesisUploadRepository: genesisUploadRepository,
 		logger:                  contextLogger,
+		logFile:                 logFile,
+		report:                  newReport(),
+		metrics:                 newUploaderMetrics(),
+		progress:                newProgressTracker(cfg.Progress, cfg.ProgressInterval, contextLogger),
 	}
 }
 
-func (egu *ExplorerGenesisUploader) Do() error {
-
-	if !egu.isEmptyDB() {
-		return errors.New("genesis has not been uploaded DB is not empty")
+// Close releases DB connections and the log file, the uploader can't be used after it
+func (egu *ExplorerGenesisUploader) Close() error {
+	var err error
+	if egu.db != nil {
+		err = egu.db.Close()
 	}
+	if egu.logFile != nil {
+		if closeErr := egu.logFile.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
 
-	start := time.Now()
-	egu.logger.Info("Getting genesis data...")
-
-	var genesis *domain.Genesis
-
-	if *file != "" {
-
-		var gf *domain.GenesisFile
-
-		jsonFile, err := os.Open(*file)
+func (egu *ExplorerGenesisUploader) Do() (err error) {
+	egu.report.Source = egu.source()
+	stopHTTP := func() {}
+	defer func() {
 		if err != nil {
-			panic(err)
+			egu.logger.Error(err)
 		}
-		defer jsonFile.Close()
+		egu.finishReport(err)
+		egu.linger()
+		stopHTTP()
+	}()
 
-		dec := json.NewDecoder(jsonFile)
-		dec.UseNumber()
+	stopHTTP, err = egu.serveHTTP()
+	if err != nil {
+		return err
+	}
 
-		err = dec.Decode(gf)
-		if err != nil {
-			panic(err)
-		}
+	if err := egu.checkSchema(); err != nil {
+		return err
+	}
 
+	if egu.env.Merge {
+		egu.logger.Info("Merge mode: genesis is layered onto existing data")
 	} else {
-		client, err := grpc_client.New(egu.env.NodeGrpc)
+		isEmpty, err := egu.isEmptyDB()
 		if err != nil {
-			panic(err)
+			return err
 		}
-		genesisResponse, err := client.Genesis()
-		if err != nil {
-			panic(err)
+		if !isEmpty {
+			return fmt.Errorf("%w: genesis has been uploaded already, run wipe to remove it or use merge mode", ErrDBNotEmpty)
 		}
+	}
 
-		egu.startBlock = genesisResponse.InitialHeight
+	start := time.Now()
+	egu.logger.Info("Getting genesis data...")
 
-		genesis = egu.convertResponseToModel(genesisResponse)
+	genesis, err := egu.loadGenesis()
+	if err != nil {
+		return err
 	}
+	egu.metrics.sourceFetch.Set(time.Since(start).Seconds())
 
 	egu.startBlock = genesis.InitialHeight
+	egu.report.ChainID = genesis.ChainID
+	egu.report.InitialHeight = genesis.InitialHeight
+
+	if err := egu.validate(genesis); err != nil {
+		return err
+	}
 
 	egu.logger.Info(fmt.Sprintf("Genesis has been downloaded. Processing time %s", time.Since(start)))
 
-	egu.logger.Info("Extracting addresses...")
-	startOperation := time.Now()
-	addresses, err := egu.extractAddresses(genesis)
+	err = egu.genesisUploadRepository.Save(&domain.GenesisUpload{
+		ChainID:       genesis.ChainID,
+		InitialHeight: genesis.InitialHeight,
+		Source:        egu.report.Source,
+		Merge:         egu.env.Merge,
+		StartedAt:     egu.report.StartedAt,
+	})
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("%w: %s", ErrDBWrite, err)
 	}
-	egu.logger.Info(fmt.Sprintf("%d addresses has been extracted. Processing time %s", len(addresses), time.Since(startOperation)))
-	startOperation = time.Now()
-	egu.saveAddresses(addresses)
-	egu.logger.Info(fmt.Sprintf("Addresses has been saved. Processing time %s", time.Since(startOperation)))
 
-	egu.logger.Info("Extracting coins...")
-	startOperation = time.Now()
-	coins, err := egu.extractCoins(genesis)
-	if err != nil {
-		panic(err)
+	if _, err := egu.runStages(genesis, true); err != nil {
+		return err
 	}
-	egu.logger.Info(fmt.Sprintf("%d coins has been extracted. Processing time %s", len(coins)+1, time.Since(startOperation)))
-	startOperation = time.Now()
-	egu.saveCoins(coins)
-	egu.logger.Info(fmt.Sprintf("Coins has been saved. Processing time %s", time.Since(startOperation)))
 
-	egu.logger.Info("Extracting validators...")
-	startOperation = time.Now()
-	validators, err := egu.extractCandidates(genesis)
-	if err != nil {
-		panic(err)
-	}
-	egu.logger.Info(fmt.Sprintf("%d validators have been extracted. Processing time %s", len(validators), time.Since(startOperation)))
-	startOperation = time.Now()
-	err = egu.saveCandidates(validators)
-	if err != nil {
-		panic(err)
+	for _, stage := range egu.report.Stages {
+		for _, issue := range stage.Issues {
+			egu.logger.WithFields(logrus.Fields{
+				"stage":   stage.Name,
+				"entity":  issue.Entity,
+				"id":      issue.ID,
+				"skipped": issue.Skipped,
+			}).Warning(issue.Reason)
+		}
 	}
-	egu.logger.Info(fmt.Sprintf("Validators has been saved. Processing time %s", time.Since(startOperation)))
 
-	egu.logger.Info("Extracting balances...")
-	startOperation = time.Now()
-	balances, err := egu.extractBalances(genesis)
-	if err != nil {
-		panic(err)
-	}
-	egu.logger.Info(fmt.Sprintf("%d balances has been extracted. Processing time %s", len(balances), time.Since(startOperation)))
-	startOperation = time.Now()
-	err = egu.saveBalances(balances)
-	if err != nil {
-		panic(err)
-	}
-	egu.logger.Info(fmt.Sprintf("Balances has been saved. Processing time %s", time.Since(startOperation)))
+	egu.logger.Info("Upload complete")
+	elapsed := time.Since(start)
+	egu.logger.Info("Processing time: ", elapsed)
+	return nil
+}
 
-	egu.logger.Info("Extracting stakes...")
-	startOperation = time.Now()
-	stakes, err := egu.extractStakes(genesis)
-	if err != nil {
-		panic(err)
+// validate checks genesis before extraction and logs every violation. Reference violations
+// are data errors: strict policy fails on them, lenient one continues and extract stages
+// skip or warn about the rows referencing unknown entities.
+func (egu *ExplorerGenesisUploader) validate(genesis *domain.Genesis) error {
+	violations := validation.Check(genesis, egu.env.MinterBaseCoin)
+	egu.report.Violations = violations
+	var fatal, references int
+	for _, v := range violations {
+		logger := egu.logger.WithFields(logrus.Fields{
+			"rule": v.Rule,
+			"path": v.Path,
+		})
+		if v.Rule == validation.RuleReference {
+			references++
+			logger.Warning(v.Message)
+			continue
+		}
+		fatal++
+		logger.Error(v.Message)
 	}
-	egu.logger.Info(fmt.Sprintf("%d stakes have been extracted. Processing time %s", len(stakes), time.Since(startOperation)))
-	startOperation = time.Now()
-	err = egu.saveStakes(stakes)
-	if err != nil {
-		panic(err)
+	if fatal > 0 {
+		return fmt.Errorf("%w: %d violations", ErrValidationFailed, len(violations))
 	}
-	egu.logger.Info(fmt.Sprintf("Stakes has been saved. Processing time %s", time.Since(startOperation)))
+	if references > 0 && egu.env.ErrorPolicy == PolicyStrict {
+		return fmt.Errorf("%w: %d reference violations under strict policy", ErrValidationFailed, references)
+	}
+	return nil
+}
 
-	egu.logger.Info("Extracting unbonds...")
-	startOperation = time.Now()
-	unbonds, err := egu.extractUnbonds(genesis)
-	if err != nil {
-		//panic(err)
-		egu.logger.Error(err)
+// source describes where genesis is loaded from
+func (egu *ExplorerGenesisUploader) source() string {
+	if egu.env.GenesisFile != "" {
+		return "file:" + egu.env.GenesisFile
 	}
-	egu.logger.Info(fmt.Sprintf("%d unbonds have been extracted. Processing time %s", len(unbonds), time.Since(startOperation)))
-	startOperation = time.Now()
-	err = egu.saveUnbonds(unbonds)
-	if err != nil {
-		egu.logger.Error(fmt.Sprintf("Unbonds saving error: %s", err))
-	} else {
-		egu.logger.Info(fmt.Sprintf("Unbonds has been saved. Processing time %s", time.Since(startOperation)))
+	return "grpc:" + egu.env.NodeGrpc
+}
+
+func (egu *ExplorerGenesisUploader) finishReport(err error) {
+	egu.report.finish(err)
+
+	success := 0.0
+	if err == nil {
+		success = 1
 	}
+	egu.metrics.success.Set(success)
+	egu.metrics.finishedAt.Set(float64(time.Now().Unix()))
+	egu.writeMetrics()
 
-	egu.logger.Info("Extracting liquidity pools...")
-	startOperation = time.Now()
-	lpList, err := egu.extractLiquidityPool(genesis)
-	if err != nil {
-		panic(err)
+	if egu.env.ReportPath == "" {
+		return
 	}
-	egu.logger.Info(fmt.Sprintf("%d liquidity pools have been extracted. Processing time %s", len(lpList), time.Since(startOperation)))
-	startOperation = time.Now()
-	err = egu.saveLiquidityPool(lpList)
-	if err != nil {
-		panic(err)
+	if err := egu.report.WriteFile(egu.env.ReportPath); err != nil {
+		egu.logger.Error(err)
+		return
 	}
-	egu.logger.Info(fmt.Sprintf("Liquidity pools has been saved. Processing time %s", time.Since(startOperation)))
+	egu.logger.Info(fmt.Sprintf("Report has been written to %s", egu.env.ReportPath))
+}
 
-	egu.logger.Info("Extracting orders...")
-	startOperation = time.Now()
-	orderList, err := egu.extractOrders(genesis)
-	if err != nil {
-		panic(err)
-	}
-	egu.logger.Info(fmt.Sprintf("%d orders has been extracted. Processing time %s", len(orderList), time.Since(startOperation)))
-	startOperation = time.Now()
-	err = egu.saveOrders(orderList)
+// loadGenesis reads genesis from the configured file or from the node
+func (egu *ExplorerGenesisUploader) loadGenesis() (*domain.Genesis, error) {
+	return egu.loadGenesisFrom(egu.source())
+}
+
+// loadGenesisFrom reads genesis from "file:<path>" or "grpc:<address>" source
+func (egu *ExplorerGenesisUploader) loadGenesisFrom(source string) (*domain.Genesis, error) {
+	genesis, err := egu.readGenesis(source)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("%w: %s", ErrSourceUnavailable, err)
 	}
-	egu.logger.Info(fmt.Sprintf("Orders has been saved. Processing time %s", time.Since(startOperation)))
+	return genesis, nil
+}
 
-	egu.logger.Info("Upload complete")
-	elapsed := time.Since(start)
-	egu.logger.Info("Processing time: ", elapsed)
-	return err
+func (egu *ExplorerGenesisUploader) readGenesis(source string) (*domain.Genesis, error) {
+	switch {
+	case strings.HasPrefix(source, "file:"):
+		jsonFile, err := os.Open(strings.TrimPrefix(source, "file:"))
+		if err != nil {
+			return nil, err
+		}
+		defer jsonFile.Close()
+
+		gf := new(domain.GenesisFile)
+		err = json.NewDecoder(jsonFile).Decode(gf)
+		if err != nil {
+			return nil, err
+		}
+		return egu.convertFileToModel(gf)
+	case strings.HasPrefix(source, "grpc:"):
+		client, err := grpc_client.New(strings.TrimPrefix(source, "grpc:"))
+		if err != nil {
+			return nil, err
+		}
+		genesisResponse, err := client.Genesis()
+		if err != nil {
+			return nil, err
+		}
+		return egu.convertResponseToModel(genesisResponse), nil
+	default:
+		return nil, fmt.Errorf("unknown genesis source %q, expected file:<path> or grpc:<address>", source)
+	}
 }
 
+// zeroAddress is inserted by every upload along with genesis addresses
+const zeroAddress = "0000000000000000000000000000000000000000"
+
 func (egu *ExplorerGenesisUploader) extractAddresses(genesis *domain.Genesis) ([]string, error) {
 	addressesMap := make(map[string]struct{})
-	addressesMap["0000000000000000000000000000000000000000"] = struct{}{}
+	addressesMap[zeroAddress] = struct{}{}
+
+	total := len(genesis.AppState.Candidates) + len(genesis.AppState.Accounts) +
+		len(genesis.AppState.Coins) + len(genesis.AppState.FrozenFunds)
+	p := egu.track("addresses", "extract", total)
+	defer egu.untrack(p)
 
 	for _, candidate := range genesis.AppState.Candidates {
 		addressesMap[helpers.RemovePrefix(candidate.RewardAddress)] = struct{}{}
@@ -257,17 +328,21 @@ func (egu *ExplorerGenesisUploader) extractAddresses(genesis *domain.Genesis) ([
 		for _, stake := range candidate.Stakes {
 			addressesMap[helpers.RemovePrefix(stake.Owner)] = struct{}{}
 		}
+		p.add(1)
 	}
 	for _, account := range genesis.AppState.Accounts {
 		addressesMap[helpers.RemovePrefix(account.Address)] = struct{}{}
+		p.add(1)
 	}
 	for _, coin := range genesis.AppState.Coins {
 		if coin.OwnerAddress != nil && *coin.OwnerAddress != "" {
 			addressesMap[helpers.RemovePrefix(*coin.OwnerAddress)] = struct{}{}
 		}
+		p.add(1)
 	}
 	for _, data := range genesis.AppState.FrozenFunds {
 		addressesMap[helpers.RemovePrefix(data.Address)] = struct{}{}
+		p.add(1)
 	}
 
 	var addresses = make([]string, len(addressesMap))
@@ -276,6 +351,8 @@ func (egu *ExplorerGenesisUploader) extractAddresses(genesis *domain.Genesis) ([
 		addresses[i] = adr
 		i++
 	}
+	// sorted, so addresses get the same ids on every upload and in SQL dump
+	sort.Strings(addresses)
 	return addresses, nil
 }
 
@@ -295,7 +372,10 @@ func (egu *ExplorerGenesisUploader) extractCoins(genesis *domain.Genesis) ([]*do
 		Version:   0,
 	}
 
+	p := egu.track("coins", "extract", len(genesis.AppState.Coins))
+	defer egu.untrack(p)
 	for _, c := range genesis.AppState.Coins {
+		p.add(1)
 		if c.ID == 0 {
 			continue
 		}
@@ -314,40 +394,137 @@ func (egu *ExplorerGenesisUploader) extractCoins(genesis *domain.Genesis) ([]*do
 		if c.OwnerAddress != nil && *c.OwnerAddress != "" {
 			addressId, err := egu.addressRepository.FindId(helpers.RemovePrefix(*c.OwnerAddress))
 			if err != nil {
-				egu.logger.Error(err)
+				if err := egu.warn("coins", "owner_address", *c.OwnerAddress, err.Error()); err != nil {
+					return nil, err
+				}
 			} else {
 				coins[i].OwnerAddressId = uint(addressId)
 			}
 		}
 		i++
 	}
-	return coins, nil
+	return coins[:i], nil
 }
 
-func (egu ExplorerGenesisUploader) extractCandidates(genesis *domain.Genesis) ([]*domain.Validator, error) {
-	var validators []*domain.Validator
-	for _, candidate := range genesis.AppState.Candidates {
-		ownerAddress, err := egu.addressRepository.FindId(helpers.RemovePrefix(candidate.OwnerAddress))
-		if err != nil {
-			egu.logger.Error(err)
+// matchCoins gives genesis coins ids of coins with the same symbols stored in DB, so merge
+// updates them instead of inserting duplicates, and keeps the mapping for dependent rows.
+// A coin whose id is taken by a coin with another symbol cannot be merged.
+func (egu *ExplorerGenesisUploader) matchCoins(coins []*domain.Coin) error {
+	stored, err := egu.coinRepository.GetAll()
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrDBUnavailable, err)
+	}
+	bySymbol := make(map[string]*domain.Coin, len(stored))
+	byId := make(map[uint]*domain.Coin, len(stored))
+	for _, c := range stored {
+		bySymbol[c.Symbol] = c
+		byId[c.ID] = c
+	}
+
+	egu.coinIds = make(map[uint64]uint64)
+	for _, coin := range coins {
+		genesisId := coin.ID
+		if c, ok := bySymbol[coin.Symbol]; ok {
+			coin.ID = c.ID
+		} else if c, ok := byId[coin.ID]; ok {
+			return fmt.Errorf("%w: coin %s has id %d which is used by coin %s", ErrDBNotEmpty, coin.Symbol, coin.ID, c.Symbol)
 		}
-		rewardAddress, err := egu.addressRepository.FindId(helpers.RemovePrefix(candidate.RewardAddress))
-		if err != nil {
-			egu.logger.Error(err)
+		if coin.ID != genesisId {
+			egu.logger.WithField("symbol", coin.Symbol).Info(fmt.Sprintf("Coin %d is stored as %d", genesisId, coin.ID))
+		}
+		egu.coinIds[uint64(genesisId)] = uint64(coin.ID)
+	}
+	return nil
+}
+
+// coinId returns DB id of a genesis coin
+func (egu *ExplorerGenesisUploader) coinId(genesisId uint64) uint64 {
+	if id, ok := egu.coinIds[genesisId]; ok {
+		return id
+	}
+	return genesisId
+}
+
+// matchValidators gives genesis validators ids of validators stored in DB with the same public
+// keys and keeps the mapping for unbonds. A validator whose id is taken by a validator with
+// another public key cannot be merged.
+func (egu *ExplorerGenesisUploader) matchValidators(validators []*domain.Validator) error {
+	stored, err := egu.validatorRepository.GetAll()
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrDBUnavailable, err)
+	}
+	keys, err := egu.validatorRepository.GetAllPk()
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrDBUnavailable, err)
+	}
+	byKey := make(map[string]uint, len(keys))
+	for _, pk := range keys {
+		byKey[pk.Key] = pk.ValidatorId
+	}
+	byId := make(map[uint]*domain.Validator, len(stored))
+	for _, v := range stored {
+		byId[v.ID] = v
+	}
+
+	egu.validatorIds = make(map[uint64]uint)
+	for _, validator := range validators {
+		genesisId := validator.ID
+		if id, ok := byKey[validator.PublicKey]; ok {
+			validator.ID = id
+		} else if v, ok := byId[validator.ID]; ok {
+			return fmt.Errorf("%w: validator %s has id %d which is used by validator %s",
+				ErrDBNotEmpty, validator.PublicKey, validator.ID, v.PublicKey)
+		}
+		if validator.ID != genesisId {
+			egu.logger.WithField("public_key", validator.PublicKey).Info(fmt.Sprintf("Validator %d is stored as %d", genesisId, validator.ID))
 		}
+		egu.validatorIds[uint64(genesisId)] = validator.ID
+	}
+	return nil
+}
 
+// validatorId returns DB id of a genesis candidate
+func (egu *ExplorerGenesisUploader) validatorId(genesisId uint64) uint {
+	if id, ok := egu.validatorIds[genesisId]; ok {
+		return id
+	}
+	return uint(genesisId)
+}
+
+func (egu ExplorerGenesisUploader) extractCandidates(genesis *domain.Genesis) ([]*domain.Validator, error) {
+	var validators []*domain.Validator
+	p := egu.track("validators", "extract", len(genesis.AppState.Candidates))
+	defer egu.untrack(p)
+	for _, candidate := range genesis.AppState.Candidates {
+		p.add(1)
 		status := uint8(candidate.Status)
 		commission := candidate.Commission
 		stake := candidate.TotalBipStake
 
 		validator := &domain.Validator{
-			ID:              uint(candidate.ID),
-			PublicKey:       helpers.RemovePrefix(candidate.PublicKey),
-			OwnerAddressID:  &ownerAddress,
-			RewardAddressID: &rewardAddress,
-			Status:          &status,
-			Commission:      &commission,
-			TotalStake:      &stake,
+			ID:         uint(candidate.ID),
+			PublicKey:  helpers.RemovePrefix(candidate.PublicKey),
+			Status:     &status,
+			Commission: &commission,
+			TotalStake: &stake,
+		}
+
+		// an unresolved address is stored as NULL, 0 would break the foreign key
+		ownerAddress, err := egu.addressRepository.FindId(helpers.RemovePrefix(candidate.OwnerAddress))
+		if err != nil {
+			if err := egu.warn("validators", "owner_address", candidate.OwnerAddress, err.Error()); err != nil {
+				return nil, err
+			}
+		} else {
+			validator.OwnerAddressID = &ownerAddress
+		}
+		rewardAddress, err := egu.addressRepository.FindId(helpers.RemovePrefix(candidate.RewardAddress))
+		if err != nil {
+			if err := egu.warn("validators", "reward_address", candidate.RewardAddress, err.Error()); err != nil {
+				return nil, err
+			}
+		} else {
+			validator.RewardAddressID = &rewardAddress
 		}
 
 		validators = append(validators, validator)
@@ -356,8 +533,16 @@ func (egu ExplorerGenesisUploader) extractCandidates(genesis *domain.Genesis) ([
 	return validators, nil
 }
 
-func (egu *ExplorerGenesisUploader) saveAddresses(addresses []string) {
+func (egu *ExplorerGenesisUploader) saveAddresses(addresses []string) error {
 	egu.logger.Info("Saving addresses to DB...")
+	p := egu.track("addresses", "save", len(addresses))
+	defer egu.untrack(p)
+	save := egu.addressRepository.SaveAll
+	if egu.env.Merge {
+		save = egu.addressRepository.UpsertAll
+	}
+	// chunks are saved one by one, so ids follow the order of addresses
+	var saveErr error
 	if len(addresses) > 0 {
 		wgAddresses := new(sync.WaitGroup)
 		chunksCount := int(math.Ceil(float64(len(addresses)) / float64(egu.env.AddressChunkSize)))
@@ -369,19 +554,29 @@ func (egu *ExplorerGenesisUploader) saveAddresses(addresses []string) {
 			}
 			wgAddresses.Add(1)
 			go func() {
-				err := egu.addressRepository.SaveAll(addresses[start:end])
-				if err != nil {
-					panic(err)
-				}
+				saveErr = egu.saveChunk("addresses", end-start, func() error {
+					return save(addresses[start:end])
+				})
 				wgAddresses.Done()
 			}()
+			wgAddresses.Wait()
+			if saveErr != nil {
+				return saveErr
+			}
 		}
-		wgAddresses.Wait()
 	}
+	return nil
 }
 
-func (egu *ExplorerGenesisUploader) saveCoins(coins []*domain.Coin) {
+func (egu *ExplorerGenesisUploader) saveCoins(coins []*domain.Coin) error {
 	egu.logger.Info("Saving coins to DB...")
+	p := egu.track("coins", "save", len(coins))
+	defer egu.untrack(p)
+	save := egu.coinRepository.SaveAll
+	if egu.env.Merge {
+		save = egu.coinRepository.UpsertAll
+	}
+	errs := new(firstError)
 	var list []*domain.Coin
 	list = append(list, coins...)
 	wgCoins := new(sync.WaitGroup)
@@ -394,23 +589,31 @@ func (egu *ExplorerGenesisUploader) saveCoins(coins []*domain.Coin) {
 		}
 		wgCoins.Add(1)
 		go func() {
-			err := egu.coinRepository.SaveAll(list[start:end])
-			if err != nil {
-				panic(err)
-			}
+			errs.set(egu.saveChunk("coins", end-start, func() error {
+				return save(list[start:end])
+			}))
 			wgCoins.Done()
 		}()
 	}
 	wgCoins.Wait()
+	return errs.err
 }
 
 func (egu *ExplorerGenesisUploader) saveCandidates(validators []*domain.Validator) error {
 	egu.logger.Info("Saving validators to DB...")
+	p := egu.track("validators", "save", len(validators))
+	defer egu.untrack(p)
+	save := egu.validatorRepository.SaveAll
+	if egu.env.Merge {
+		save = egu.validatorRepository.UpsertAll
+	}
 
 	if len(validators) > 0 {
-		err := egu.validatorRepository.SaveAll(validators)
+		err := egu.saveChunk("validators", len(validators), func() error {
+			return save(validators)
+		})
 		if err != nil {
-			panic(err)
+			return err
 		}
 
 		var vpk []*domain.ValidatorPublicKeys
@@ -422,9 +625,13 @@ func (egu *ExplorerGenesisUploader) saveCandidates(validators []*domain.Validato
 			})
 		}
 
-		err = egu.validatorRepository.SaveAllPk(vpk)
+		savePk := egu.validatorRepository.SaveAllPk
+		if egu.env.Merge {
+			savePk = egu.validatorRepository.UpsertAllPk
+		}
+		err = savePk(vpk)
 		if err != nil {
-			panic(err)
+			return egu.fail("validators", len(vpk), err)
 		}
 	}
 	return nil
@@ -432,10 +639,14 @@ func (egu *ExplorerGenesisUploader) saveCandidates(validators []*domain.Validato
 
 func (egu *ExplorerGenesisUploader) extractBalances(genesis *domain.Genesis) ([]*domain.Balance, error) {
 	chunkSize := 1000
+	errs := new(firstError)
 	var results []*domain.Balance
 	ch := make(chan []*domain.Balance)
 
 	if len(genesis.AppState.Accounts) > 0 {
+		coins := genesisCoins(genesis)
+		p := egu.track("balances", "extract", len(genesis.AppState.Accounts))
+		defer egu.untrack(p)
 		wg := new(sync.WaitGroup)
 		wg.Add(1)
 		go func() {
@@ -458,30 +669,49 @@ func (egu *ExplorerGenesisUploader) extractBalances(genesis *domain.Genesis) ([]
 				for _, account := range genesis.AppState.Accounts[start:end] {
 					addressId, err := egu.addressRepository.FindId(helpers.RemovePrefix(account.Address))
 					if err != nil {
-						egu.logger.Error(err)
+						errs.set(egu.skip("balances", "account", account.Address, err.Error()))
 						continue
 					}
 					for _, bls := range account.Balance {
+						if _, ok := coins[bls.Coin]; !ok {
+							errs.set(egu.skip("balances", "balance", account.Address, fmt.Sprintf("unknown coin %d", bls.Coin)))
+							continue
+						}
 						balances = append(balances, &domain.Balance{
-							CoinID:    uint64(bls.Coin),
+							CoinID:    egu.coinId(bls.Coin),
 							AddressID: addressId,
 							Value:     bls.Value,
 						})
 					}
 				}
 				ch <- balances
+				p.add(end - start)
 				wgBalances.Done()
 			}()
 		}
 		wgBalances.Wait()
 		close(ch)
 		wg.Wait()
+		sort.Slice(results, func(i, j int) bool {
+			if results[i].AddressID != results[j].AddressID {
+				return results[i].AddressID < results[j].AddressID
+			}
+			return results[i].CoinID < results[j].CoinID
+		})
 	}
-	return results, nil
+	return results, errs.err
 }
 
 func (egu *ExplorerGenesisUploader) saveBalances(balances []*domain.Balance) error {
 	egu.logger.Info("Saving balances to DB...")
+	p := egu.track("balances", "save", len(balances))
+	defer egu.untrack(p)
+	save := egu.balanceRepository.SaveAll
+	if egu.env.Merge {
+		save = egu.balanceRepository.UpsertAll
+	}
+
+	var saveErr error
 	if len(balances) > 0 {
 		wgBalances := new(sync.WaitGroup)
 		chunksCount := int(math.Ceil(float64(len(balances)) / float64(egu.env.BalanceChunkSize)))
@@ -493,13 +723,15 @@ func (egu *ExplorerGenesisUploader) saveBalances(balances []*domain.Balance) err
 			}
 			wgBalances.Add(1)
 			go func() {
-				err := egu.balanceRepository.SaveAll(balances[start:end])
-				if err != nil {
-					egu.logger.Error(err)
-				}
+				saveErr = egu.saveChunk("balances", end-start, func() error {
+					return save(balances[start:end])
+				})
 				wgBalances.Done()
 			}()
 			wgBalances.Wait()
+			if saveErr != nil {
+				return saveErr
+			}
 		}
 	}
 	return nil
@@ -507,18 +739,38 @@ func (egu *ExplorerGenesisUploader) saveBalances(balances []*domain.Balance) err
 
 func (egu *ExplorerGenesisUploader) extractStakes(genesis *domain.Genesis) ([]*domain.Stake, error) {
 	var stakes []*domain.Stake
+	coins := genesisCoins(genesis)
+	total := 0
+	for _, candidate := range genesis.AppState.Candidates {
+		total += len(candidate.Stakes)
+	}
+	p := egu.track("stakes", "extract", total)
+	defer egu.untrack(p)
 	for _, candidate := range genesis.AppState.Candidates {
 		for _, stake := range candidate.Stakes {
+			p.add(1)
+			if _, ok := coins[stake.Coin]; !ok {
+				if err := egu.skip("stakes", "stake", stake.Owner, fmt.Sprintf("unknown coin %d", stake.Coin)); err != nil {
+					return nil, err
+				}
+				continue
+			}
 			ownerId, err := egu.addressRepository.FindId(helpers.RemovePrefix(stake.Owner))
 			if err != nil {
-				egu.logger.Error(err)
+				if err := egu.skip("stakes", "owner_address", stake.Owner, err.Error()); err != nil {
+					return nil, err
+				}
+				continue
 			}
 			validatorId, err := egu.validatorRepository.FindIdByPk(helpers.RemovePrefix(candidate.PublicKey))
 			if err != nil {
-				egu.logger.Error(err)
+				if err := egu.skip("stakes", "validator", candidate.PublicKey, err.Error()); err != nil {
+					return nil, err
+				}
+				continue
 			}
 			stakes = append(stakes, &domain.Stake{
-				CoinID:         uint64(stake.Coin),
+				CoinID:         egu.coinId(stake.Coin),
 				OwnerAddressID: ownerId,
 				ValidatorID:    validatorId,
 				Value:          stake.Value,
@@ -531,6 +783,14 @@ func (egu *ExplorerGenesisUploader) extractStakes(genesis *domain.Genesis) ([]*d
 
 func (egu *ExplorerGenesisUploader) saveStakes(stakes []*domain.Stake) error {
 	egu.logger.Info("Saving stakes to DB...")
+	p := egu.track("stakes", "save", len(stakes))
+	defer egu.untrack(p)
+	save := egu.validatorRepository.SaveAllStakes
+	if egu.env.Merge {
+		save = egu.validatorRepository.UpsertAllStakes
+	}
+
+	var saveErr error
 	if len(stakes) > 0 {
 		wgStakes := new(sync.WaitGroup)
 		chunksCount := int(math.Ceil(float64(len(stakes)) / float64(egu.env.StakeChunkSize)))
@@ -542,146 +802,381 @@ func (egu *ExplorerGenesisUploader) saveStakes(stakes []*domain.Stake) error {
 			}
 			wgStakes.Add(1)
 			go func() {
-				err := egu.validatorRepository.SaveAllStakes(stakes[start:end])
-				if err != nil {
-					egu.logger.Error(err)
-				}
+				saveErr = egu.saveChunk("stakes", end-start, func() error {
+					return save(stakes[start:end])
+				})
 				wgStakes.Done()
 			}()
 			wgStakes.Wait()
+			if saveErr != nil {
+				return saveErr
+			}
 		}
 	}
 	return nil
 }
 
-func (egu *ExplorerGenesisUploader) isEmptyDB() bool {
+func (egu *ExplorerGenesisUploader) isEmptyDB() (bool, error) {
 	addressesCount, err := egu.addressRepository.GetAddressesCount()
 	if err != nil {
-		panic(err)
+		return false, fmt.Errorf("%w: %s", ErrDBUnavailable, err)
 	}
 	balancesCount, err := egu.balanceRepository.GetBalancesCount()
 	if err != nil {
-		panic(err)
+		return false, fmt.Errorf("%w: %s", ErrDBUnavailable, err)
 	}
 	validatorCount, err := egu.validatorRepository.GetValidatorsCount()
 	if err != nil {
-		panic(err)
+		return false, fmt.Errorf("%w: %s", ErrDBUnavailable, err)
 	}
-	return addressesCount == 0 && balancesCount == 0 && validatorCount == 0
+	return addressesCount == 0 && balancesCount == 0 && validatorCount == 0, nil
 }
 
 func (egu *ExplorerGenesisUploader) extractUnbonds(genesis *domain.Genesis) ([]*domain.Unbond, error) {
+	candidates := make(map[uint64]struct{})
+	for _, c := range genesis.AppState.Candidates {
+		candidates[c.ID] = struct{}{}
+	}
+
 	var unbonds []*domain.Unbond
+	coins := genesisCoins(genesis)
+	p := egu.track("unbonds", "extract", len(genesis.AppState.FrozenFunds))
+	defer egu.untrack(p)
 	for _, data := range genesis.AppState.FrozenFunds {
+		p.add(1)
+		if _, ok := coins[data.Coin]; !ok {
+			if err := egu.skip("unbonds", "frozen_fund", data.Address, fmt.Sprintf("unknown coin %d", data.Coin)); err != nil {
+				return nil, err
+			}
+			continue
+		}
 		addressId, err := egu.addressRepository.FindId(helpers.RemovePrefix(data.Address))
 		if err != nil {
-			egu.logger.WithField("address", data.Address).Error(err)
+			if err := egu.skip("unbonds", "address", data.Address, err.Error()); err != nil {
+				return nil, err
+			}
 			continue
 		}
-		if data.CandidateKey == nil {
-			continue
+
+		unbond := &domain.Unbond{
+			AddressId:     uint(addressId),
+			BlockId:       uint(data.Height),
+			UnlockBlockId: uint(data.Height),
+			CoinId:        uint(egu.coinId(data.Coin)),
+			Value:         data.Value,
 		}
-		unbonds = append(unbonds, &domain.Unbond{
-			AddressId:   uint(addressId),
-			ValidatorId: uint(data.CandidateID),
-			BlockId:     uint(data.Height),
-			CoinId:      uint(data.Coin),
-			Value:       data.Value,
-		})
+
+		switch {
+		case data.MoveToCandidateID != nil:
+			unbond.Type = domain.UnbondTypeMoveStake
+		case data.CandidateKey != nil || data.CandidateID != 0:
+			unbond.Type = domain.UnbondTypeUnbond
+		default:
+			unbond.Type = domain.UnbondTypeLock
+		}
+
+		if data.CandidateID != 0 {
+			if _, ok := candidates[data.CandidateID]; ok {
+				validatorId := egu.validatorId(data.CandidateID)
+				unbond.ValidatorId = &validatorId
+			} else {
+				err := egu.warn("unbonds", "candidate", data.CandidateID, "frozen fund of unknown candidate is saved without validator")
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		unbonds = append(unbonds, unbond)
 	}
 	return unbonds, nil
 }
 
 func (egu *ExplorerGenesisUploader) saveUnbonds(unbonds []*domain.Unbond) error {
 	egu.logger.Info("Saving unbonds to DB...")
-
-	var list []*domain.Unbond
-	for _, u := range unbonds {
-		_, err := egu.validatorRepository.GetById(u.ValidatorId)
-		if err == nil {
-			list = append(list, u)
-		}
+	p := egu.track("unbonds", "save", len(unbonds))
+	defer egu.untrack(p)
+	save := egu.validatorRepository.SaveAllUnbonds
+	if egu.env.Merge {
+		save = egu.validatorRepository.UpsertAllUnbonds
 	}
 
-	if len(list) > 0 {
+	var saveErr error
+	if len(unbonds) > 0 {
 		wgStakes := new(sync.WaitGroup)
-		chunksCount := int(math.Ceil(float64(len(list)) / float64(egu.env.StakeChunkSize)))
+		chunksCount := int(math.Ceil(float64(len(unbonds)) / float64(egu.env.StakeChunkSize)))
 		for i := 0; i < chunksCount; i++ {
 			start := int(egu.env.StakeChunkSize) * i
 			end := start + int(egu.env.StakeChunkSize)
-			if end > len(list) {
-				end = len(list)
+			if end > len(unbonds) {
+				end = len(unbonds)
 			}
 			wgStakes.Add(1)
 			go func() {
-				err := egu.validatorRepository.SaveAllUnbonds(list[start:end])
-				if err != nil {
-					egu.logger.Error(err)
-				}
+				saveErr = egu.saveChunk("unbonds", end-start, func() error {
+					return save(unbonds[start:end])
+				})
 				wgStakes.Done()
 			}()
 			wgStakes.Wait()
+			if saveErr != nil {
+				return saveErr
+			}
 		}
 	}
 
-	if len(unbonds) == len(list) {
-		return errors.New("unbonds has not been saved")
-	} else if len(unbonds)-len(list) > 0 {
-		egu.logger.Warning(fmt.Sprintf("%d unbonds has been skiped", len(unbonds)-len(list)))
+	return nil
+}
+
+// genesisCoins returns ids of coins genesis defines, the base coin included
+func genesisCoins(genesis *domain.Genesis) map[uint64]struct{} {
+	coins := map[uint64]struct{}{baseCoinId: {}}
+	for _, c := range genesis.AppState.Coins {
+		coins[c.ID] = struct{}{}
 	}
+	return coins
+}
 
-	return nil
+// poolTokens maps pool id to its token among extracted coins
+func (egu *ExplorerGenesisUploader) poolTokens(coins []*domain.Coin) map[uint64]*domain.Coin {
+	tokens := make(map[uint64]*domain.Coin)
+	for _, c := range coins {
+		if poolId, ok := domain.ParsePoolTokenSymbol(egu.env.PoolTokenPrefix, c.Symbol); ok {
+			tokens[poolId] = c
+		}
+	}
+	return tokens
 }
 
-func (egu *ExplorerGenesisUploader) extractLiquidityPool(genesis *domain.Genesis) ([]*domain.LiquidityPool, error) {
+func (egu *ExplorerGenesisUploader) extractLiquidityPool(genesis *domain.Genesis, coins []*domain.Coin) ([]*domain.LiquidityPool, error) {
 	var list []*domain.LiquidityPool
+	pricer := newBasePricer(genesis)
+	poolTokens := egu.poolTokens(coins)
+	known := genesisCoins(genesis)
+	p := egu.track("liquidity_pools", "extract", len(genesis.AppState.Pools))
+	defer egu.untrack(p)
 	for _, data := range genesis.AppState.Pools {
+		p.add(1)
+		_, ok0 := known[data.Coin0]
+		_, ok1 := known[data.Coin1]
+		if !ok0 || !ok1 || data.Coin0 == data.Coin1 {
+			err := egu.skip("liquidity_pools", "pool", data.ID, fmt.Sprintf("invalid pair of coins %d and %d", data.Coin0, data.Coin1))
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
 
-		token, err := egu.coinRepository.FindBySymbol(fmt.Sprintf("LP-%d", data.ID))
-		if err != nil {
-			egu.logger.WithField("pool_id", data.ID).Error(err)
+		token, ok := poolTokens[data.ID]
+		if !ok {
+			err := egu.skip("liquidity_pools", "pool", data.ID,
+				fmt.Sprintf("pool token %s not found", domain.PoolTokenSymbol(egu.env.PoolTokenPrefix, data.ID)))
+			if err != nil {
+				return nil, err
+			}
 			continue
 		}
 
+		// an empty value is stored as NULL, a half-priced pool has no base coin value
+		liquidityBip, unpriced := pricer.liquidityBip(data)
+		if len(unpriced) > 0 {
+			err := egu.warn("liquidity_pools", "pool", data.ID,
+				fmt.Sprintf("coins %v have no base coin price, liquidity_bip is left empty", unpriced))
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		list = append(list, &domain.LiquidityPool{
 			Id:               data.ID,
 			TokenId:          uint64(token.ID),
-			FirstCoinId:      data.Coin0,
-			SecondCoinId:     data.Coin1,
+			FirstCoinId:      egu.coinId(data.Coin0),
+			SecondCoinId:     egu.coinId(data.Coin1),
 			FirstCoinVolume:  data.Reserve0,
 			SecondCoinVolume: data.Reserve1,
 			Liquidity:        token.Volume,
+			LiquidityBip:     liquidityBip,
 			UpdatedAtBlockId: genesis.InitialHeight,
 		})
 	}
 	return list, nil
 }
 
+// matchPools gives genesis pools ids of pools stored in DB with the same pair of coins and
+// keeps the mapping for orders. A pool whose id is taken by a pool of another pair cannot be merged.
+func (egu *ExplorerGenesisUploader) matchPools(pools []*domain.LiquidityPool) error {
+	stored, err := egu.liquidityPoolRepository.GetAll()
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrDBUnavailable, err)
+	}
+	byPair := make(map[[2]uint64]uint64, len(stored))
+	byId := make(map[uint64]*domain.LiquidityPool, len(stored))
+	for _, p := range stored {
+		byPair[coinPair(p)] = p.Id
+		byId[p.Id] = p
+	}
+
+	egu.poolIds = make(map[uint64]uint64)
+	for _, pool := range pools {
+		genesisId := pool.Id
+		if id, ok := byPair[coinPair(pool)]; ok {
+			pool.Id = id
+		} else if p, ok := byId[pool.Id]; ok {
+			return fmt.Errorf("%w: pool of coins %d and %d has id %d which is used by pool of coins %d and %d",
+				ErrDBNotEmpty, pool.FirstCoinId, pool.SecondCoinId, pool.Id, p.FirstCoinId, p.SecondCoinId)
+		}
+		if pool.Id != genesisId {
+			egu.logger.WithField("token_id", pool.TokenId).Info(fmt.Sprintf("Pool %d is stored as %d", genesisId, pool.Id))
+		}
+		egu.poolIds[genesisId] = pool.Id
+	}
+	return nil
+}
+
+// coinPair returns coins of the pool in ascending order
+func coinPair(pool *domain.LiquidityPool) [2]uint64 {
+	if pool.FirstCoinId > pool.SecondCoinId {
+		return [2]uint64{pool.SecondCoinId, pool.FirstCoinId}
+	}
+	return [2]uint64{pool.FirstCoinId, pool.SecondCoinId}
+}
+
+// poolId returns DB id of a genesis pool
+func (egu *ExplorerGenesisUploader) poolId(genesisId uint64) uint64 {
+	if id, ok := egu.poolIds[genesisId]; ok {
+		return id
+	}
+	return genesisId
+}
+
 func (egu *ExplorerGenesisUploader) saveLiquidityPool(pools []*domain.LiquidityPool) error {
 	egu.logger.Info("Saving liquidity pool to DB...")
+	p := egu.track("liquidity_pools", "save", len(pools))
+	defer egu.untrack(p)
+	save := egu.liquidityPoolRepository.SaveAll
+	if egu.env.Merge {
+		save = egu.liquidityPoolRepository.UpsertAll
+	}
 	if len(pools) > 0 {
-		err := egu.liquidityPoolRepository.SaveAll(pools)
-		if err != nil {
-			egu.logger.Error(err)
+		return egu.saveChunk("liquidity_pools", len(pools), func() error {
+			return save(pools)
+		})
+	}
+	return nil
+}
+
+// extractAddressLiquidityPools derives providers' shares from balances of pool tokens,
+// at genesis a provider's liquidity is exactly the balance of the pool token
+func (egu *ExplorerGenesisUploader) extractAddressLiquidityPools(genesis *domain.Genesis, pools []*domain.LiquidityPool) ([]*domain.AddressLiquidityPool, error) {
+	poolTokens := make(map[uint64]uint64)
+	for _, pool := range pools {
+		poolTokens[pool.TokenId] = pool.Id
+	}
+
+	var list []*domain.AddressLiquidityPool
+	if len(poolTokens) == 0 {
+		return list, nil
+	}
+
+	p := egu.track("address_liquidity_pools", "extract", len(genesis.AppState.Accounts))
+	defer egu.untrack(p)
+	for _, account := range genesis.AppState.Accounts {
+		p.add(1)
+		for _, bls := range account.Balance {
+			poolId, ok := poolTokens[egu.coinId(bls.Coin)]
+			if !ok {
+				continue
+			}
+			addressId, err := egu.addressRepository.FindId(helpers.RemovePrefix(account.Address))
+			if err != nil {
+				reason := fmt.Sprintf("pool %d: %s", poolId, err)
+				if err := egu.skip("address_liquidity_pools", "account", account.Address, reason); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			list = append(list, &domain.AddressLiquidityPool{
+				LiquidityPoolId: poolId,
+				AddressId:       addressId,
+				Liquidity:       bls.Value,
+			})
+		}
+	}
+	return list, nil
+}
+
+func (egu *ExplorerGenesisUploader) saveAddressLiquidityPools(list []*domain.AddressLiquidityPool) error {
+	egu.logger.Info("Saving address liquidity pools to DB...")
+	p := egu.track("address_liquidity_pools", "save", len(list))
+	defer egu.untrack(p)
+	save := egu.liquidityPoolRepository.SaveAllAddressLiquidityPools
+	if egu.env.Merge {
+		save = egu.liquidityPoolRepository.UpsertAllAddressLiquidityPools
+	}
+
+	var saveErr error
+	if len(list) > 0 {
+		wg := new(sync.WaitGroup)
+		chunksCount := int(math.Ceil(float64(len(list)) / float64(egu.env.BalanceChunkSize)))
+		for i := 0; i < chunksCount; i++ {
+			start := int(egu.env.BalanceChunkSize) * i
+			end := start + int(egu.env.BalanceChunkSize)
+			if end > len(list) {
+				end = len(list)
+			}
+			wg.Add(1)
+			go func() {
+				saveErr = egu.saveChunk("address_liquidity_pools", end-start, func() error {
+					return save(list[start:end])
+				})
+				wg.Done()
+			}()
+			wg.Wait()
+			if saveErr != nil {
+				return saveErr
+			}
 		}
 	}
 	return nil
 }
 
-func (egu *ExplorerGenesisUploader) extractOrders(genesis *domain.Genesis) ([]domain.Order, error) {
+func (egu *ExplorerGenesisUploader) extractOrders(genesis *domain.Genesis, pools []*domain.LiquidityPool) ([]domain.Order, error) {
 	var list []domain.Order
 	var orderMap sync.Map
 	var wg sync.WaitGroup
+	errs := new(firstError)
+
+	extracted := make(map[uint64]struct{}, len(pools))
+	for _, pool := range pools {
+		extracted[pool.Id] = struct{}{}
+	}
 
+	total := 0
 	for _, pool := range genesis.AppState.Pools {
+		total += len(pool.Orders)
+	}
+	p := egu.track("orders", "extract", total)
+	defer egu.untrack(p)
+
+	for _, pool := range genesis.AppState.Pools {
+		if _, ok := extracted[egu.poolId(pool.ID)]; !ok {
+			p.add(len(pool.Orders))
+			for _, o := range pool.Orders {
+				if err := egu.skip("orders", "order", o.Id, fmt.Sprintf("pool %d is not uploaded", pool.ID)); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
 		wg.Add(len(pool.Orders))
 		for _, o := range pool.Orders {
-			go func(ord domain.GenesisOrder) {
+			go func(pool domain.Pool, ord domain.GenesisOrder) {
 				defer wg.Done()
+				defer p.add(1)
 
 				addressId, err := egu.addressRepository.FindId(helpers.RemovePrefix(ord.Owner))
 				if err != nil {
-					egu.logger.Error(err)
+					errs.set(egu.skip("orders", "order", ord.Id, err.Error()))
 					return
 				}
 
@@ -689,38 +1184,33 @@ func (egu *ExplorerGenesisUploader) extractOrders(genesis *domain.Genesis) ([]do
 					Id:              ord.Id,
 					AddressId:       addressId,
 					CreatedAtBlock:  ord.Height,
-					LiquidityPoolId: pool.ID,
+					LiquidityPoolId: egu.poolId(pool.ID),
 					Status:          1,
 				}
 
 				if ord.IsSale {
-					order.CoinSellId = pool.Coin0
+					order.CoinSellId = egu.coinId(pool.Coin0)
 					order.CoinSellVolume = ord.Volume0
 
-					order.CoinBuyId = pool.Coin1
+					order.CoinBuyId = egu.coinId(pool.Coin1)
 					order.CoinBuyVolume = ord.Volume1
 				} else {
-					order.CoinSellId = pool.Coin1
+					order.CoinSellId = egu.coinId(pool.Coin1)
 					order.CoinSellVolume = ord.Volume1
 
-					order.CoinBuyId = pool.Coin0
+					order.CoinBuyId = egu.coinId(pool.Coin0)
 					order.CoinBuyVolume = ord.Volume0
 				}
 
-				sell, ok := big.NewFloat(0).SetString(order.CoinSellVolume)
-				if !ok {
-					egu.logger.Error("can't convert to big.int")
-					return
-				}
-				buy, ok := big.NewFloat(0).SetString(order.CoinBuyVolume)
-				if !ok {
-					egu.logger.Error("can't convert to big.int")
+				order.Price, err = orderPrice(order.CoinSellVolume, order.CoinBuyVolume)
+				if errors.Is(err, errOrderPriceUnderflow) {
+					errs.set(egu.alter("orders", "order", ord.Id, err.Error()))
+				} else if err != nil {
+					errs.set(egu.skip("orders", "order", ord.Id, err.Error()))
 					return
 				}
-				price := sell.Quo(sell, buy)
-				order.Price = price.Text('f', 18)
 				orderMap.Store(ord.Id, order)
-			}(o)
+			}(pool, o)
 		}
 	}
 	wg.Wait()
@@ -728,13 +1218,44 @@ func (egu *ExplorerGenesisUploader) extractOrders(genesis *domain.Genesis) ([]do
 		list = append(list, v.(domain.Order))
 		return true
 	})
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Id < list[j].Id
+	})
 
-	return list, nil
+	return list, errs.err
+}
+
+// checkOrders fails if an order id is taken by an order of another pool, orders have
+// no other key to be matched by
+func (egu *ExplorerGenesisUploader) checkOrders(orders []domain.Order) error {
+	stored, err := egu.liquidityPoolRepository.GetAllOrders()
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrDBUnavailable, err)
+	}
+	byId := make(map[uint64]domain.Order, len(stored))
+	for _, o := range stored {
+		byId[o.Id] = o
+	}
+	for _, order := range orders {
+		if o, ok := byId[order.Id]; ok && o.LiquidityPoolId != order.LiquidityPoolId {
+			return fmt.Errorf("%w: order %d of pool %d has id which is used by order of pool %d",
+				ErrDBNotEmpty, order.Id, order.LiquidityPoolId, o.LiquidityPoolId)
+		}
+	}
+	return nil
 }
 
 func (egu *ExplorerGenesisUploader) saveOrders(orders []domain.Order) error {
 	chunkSize := 1000
 	egu.logger.Info("Saving orders to DB...")
+	p := egu.track("orders", "save", len(orders))
+	defer egu.untrack(p)
+	save := egu.liquidityPoolRepository.SaveAllOrders
+	if egu.env.Merge {
+		save = egu.liquidityPoolRepository.UpsertAllOrders
+	}
+
+	var saveErr error
 
 	if len(orders) > 0 {
 		wgStakes := new(sync.WaitGroup)
@@ -747,13 +1268,15 @@ func (egu *ExplorerGenesisUploader) saveOrders(orders []domain.Order) error {
 			}
 			wgStakes.Add(1)
 			go func() {
-				err := egu.liquidityPoolRepository.SaveAllOrders(orders[start:end])
-				if err != nil {
-					egu.logger.Error(err)
-				}
+				saveErr = egu.saveChunk("orders", end-start, func() error {
+					return save(orders[start:end])
+				})
 				wgStakes.Done()
 			}()
 			wgStakes.Wait()
+			if saveErr != nil {
+				return saveErr
+			}
 		}
 	}
 	return nil
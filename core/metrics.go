@@ -0,0 +1,92 @@
+package core
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+type uploaderMetrics struct {
+	registry      *prometheus.Registry
+	rows          *prometheus.CounterVec
+	errors        *prometheus.CounterVec
+	chunkDuration *prometheus.HistogramVec
+	stageDuration *prometheus.GaugeVec
+	sourceFetch   prometheus.Gauge
+	success       prometheus.Gauge
+	finishedAt    prometheus.Gauge
+}
+
+func newUploaderMetrics() *uploaderMetrics {
+	m := &uploaderMetrics{
+		registry: prometheus.NewRegistry(),
+		rows: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "genesis_uploader_rows_total",
+			Help: "Rows processed per table by result: extracted, saved, skipped, altered, failed.",
+		}, []string{"table", "result"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "genesis_uploader_errors_total",
+			Help: "Data and DB errors per table.",
+		}, []string{"table"}),
+		chunkDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "genesis_uploader_chunk_insert_duration_seconds",
+			Help:    "Duration of a chunk insert.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"table"}),
+		stageDuration: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "genesis_uploader_stage_duration_seconds",
+			Help: "Duration of a stage phase: extract or save.",
+		}, []string{"table", "phase"}),
+		sourceFetch: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "genesis_uploader_source_fetch_duration_seconds",
+			Help: "Duration of genesis loading from the source.",
+		}),
+		success: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "genesis_uploader_last_run_success",
+			Help: "1 if the last run succeeded, 0 otherwise.",
+		}),
+		finishedAt: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "genesis_uploader_last_run_timestamp_seconds",
+			Help: "Unix time the last run finished.",
+		}),
+	}
+	m.registry.MustRegister(m.rows, m.errors, m.chunkDuration, m.stageDuration, m.sourceFetch, m.success, m.finishedAt)
+	return m
+}
+
+// extracted records extract phase of the stage
+func (egu *ExplorerGenesisUploader) extracted(stage string, rows int, duration time.Duration) {
+	egu.report.extracted(stage, rows, duration)
+	egu.metrics.rows.WithLabelValues(stage, "extracted").Add(float64(rows))
+	egu.metrics.stageDuration.WithLabelValues(stage, "extract").Set(duration.Seconds())
+}
+
+// savedIn records save phase duration of the stage
+func (egu *ExplorerGenesisUploader) savedIn(stage string, duration time.Duration) {
+	egu.report.saveDuration(stage, duration)
+	egu.metrics.stageDuration.WithLabelValues(stage, "save").Set(duration.Seconds())
+	egu.writeMetrics()
+}
+
+// saveChunk inserts a chunk of rows, records its result and applies error policy
+func (egu *ExplorerGenesisUploader) saveChunk(stage string, rows int, insert func() error) error {
+	start := time.Now()
+	err := insert()
+	egu.metrics.chunkDuration.WithLabelValues(stage).Observe(time.Since(start).Seconds())
+	egu.advance(rows)
+	if err != nil {
+		return egu.fail(stage, rows, err)
+	}
+	egu.report.saved(stage, rows)
+	egu.metrics.rows.WithLabelValues(stage, "saved").Add(float64(rows))
+	return nil
+}
+
+// writeMetrics writes metrics for the node exporter textfile collector if configured
+func (egu *ExplorerGenesisUploader) writeMetrics() {
+	if egu.env.MetricsTextfile == "" {
+		return
+	}
+	if err := prometheus.WriteToTextfile(egu.env.MetricsTextfile, egu.metrics.registry); err != nil {
+		egu.logger.Error(err)
+	}
+}
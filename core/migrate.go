@@ -0,0 +1,68 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MinterTeam/explorer-genesis-uploader/database"
+	"github.com/MinterTeam/explorer-genesis-uploader/domain"
+	"github.com/go-pg/pg/v10"
+	"io/fs"
+	"time"
+)
+
+// SQLSTATE codes of a schema without migrations
+const (
+	pgUndefinedTable  = "42P01"
+	pgUndefinedColumn = "42703"
+)
+
+// Migrate applies schema changes the uploader relies on
+func (egu *ExplorerGenesisUploader) Migrate() error {
+	start := time.Now()
+	files, err := fs.Glob(database.Migrations, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+
+	for _, name := range files {
+		script, err := fs.ReadFile(database.Migrations, name)
+		if err != nil {
+			return err
+		}
+		egu.logger.Info(fmt.Sprintf("Applying %s...", name))
+		if err := egu.schemaRepository.Exec(string(script)); err != nil {
+			err = fmt.Errorf("%w: %s: %s", ErrDBWrite, name, err)
+			egu.logger.Error(err)
+			return err
+		}
+	}
+
+	egu.logger.Info(fmt.Sprintf("%d migrations have been applied. Processing time %s", len(files), time.Since(start)))
+	return nil
+}
+
+// checkSchema fails when a table or column the upload writes to is missing,
+// e.g. in a DB created from db.sql without migrations
+func (egu *ExplorerGenesisUploader) checkSchema() error {
+	err := egu.schemaRepository.Probe(
+		&[]*domain.GenesisUpload{},
+		&[]*domain.Address{},
+		&[]*domain.Coin{},
+		&[]*domain.Validator{},
+		&[]*domain.ValidatorPublicKeys{},
+		&[]*domain.Balance{},
+		&[]*domain.Stake{},
+		&[]*domain.Unbond{},
+		&[]*domain.LiquidityPool{},
+		&[]*domain.AddressLiquidityPool{},
+		&[]domain.Order{},
+	)
+	var pgErr pg.Error
+	if errors.As(err, &pgErr) && (pgErr.Field('C') == pgUndefinedTable || pgErr.Field('C') == pgUndefinedColumn) {
+		return fmt.Errorf("%w: %s, create the schema from database/db.sql and run migrate", ErrDBUnavailable, pgErr.Field('M'))
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrDBUnavailable, err)
+	}
+	return nil
+}
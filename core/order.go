@@ -0,0 +1,55 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// orders.price is numeric(25,18)
+const (
+	orderPricePrecision = 25
+	orderPriceScale     = 18
+)
+
+var (
+	errOrderZeroBuyVolume  = errors.New("order price: buy volume is zero")
+	errOrderPriceOverflow  = errors.New("order price: value does not fit numeric(25,18)")
+	errOrderPriceUnderflow = errors.New("order price: non-zero value rounds to zero in numeric(25,18)")
+)
+
+// orderPriceMin is the smallest positive value of numeric(25,18)
+var orderPriceMin = "0." + strings.Repeat("0", orderPriceScale-1) + "1"
+
+// orderPrice returns exact sell/buy ratio rounded to orderPriceScale digits
+// after the point. The last digit is rounded to nearest, with halves rounded
+// away from zero. A positive price which rounds to zero is clamped to
+// orderPriceMin and returned along with errOrderPriceUnderflow.
+func orderPrice(sellVolume, buyVolume string) (string, error) {
+	sell, ok := new(big.Int).SetString(sellVolume, 10)
+	if !ok {
+		return "", fmt.Errorf("order price: invalid sell volume %q", sellVolume)
+	}
+	buy, ok := new(big.Int).SetString(buyVolume, 10)
+	if !ok {
+		return "", fmt.Errorf("order price: invalid buy volume %q", buyVolume)
+	}
+	if buy.Sign() == 0 {
+		return "", errOrderZeroBuyVolume
+	}
+
+	exact := new(big.Rat).SetFrac(sell, buy)
+	price := exact.FloatString(orderPriceScale)
+
+	integer := strings.TrimPrefix(price[:strings.IndexByte(price, '.')], "-")
+	if len(integer) > orderPricePrecision-orderPriceScale {
+		return "", fmt.Errorf("%w: %s/%s", errOrderPriceOverflow, sellVolume, buyVolume)
+	}
+
+	if exact.Sign() != 0 && strings.Trim(price, "-0.") == "" {
+		return orderPriceMin, fmt.Errorf("%w: %s/%s, stored as %s", errOrderPriceUnderflow, sellVolume, buyVolume, orderPriceMin)
+	}
+
+	return price, nil
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOrderPrice(t *testing.T) {
+	tests := []struct {
+		name  string
+		sell  string
+		buy   string
+		price string
+		err   error
+	}{
+		{"integer", "10", "5", "2.000000000000000000", nil},
+		{"fraction", "10", "4", "2.500000000000000000", nil},
+		{"zero sell volume", "0", "5", "0.000000000000000000", nil},
+		{"18 digits rounded down", "1", "3", "0.333333333333333333", nil},
+		{"18 digits rounded up", "2", "3", "0.666666666666666667", nil},
+		{"half rounded away from zero", "1", "2000000000000000000", "0.000000000000000001", nil},
+		{"underflow", "1", "2000000000000000001", orderPriceMin, errOrderPriceUnderflow},
+		{"deep underflow", "1", "10000000000000000000000", orderPriceMin, errOrderPriceUnderflow},
+		{"largest integer part", "9999999", "1", "9999999.000000000000000000", nil},
+		{"overflow", "10000000", "1", "", errOrderPriceOverflow},
+		{"overflow after rounding", "99999999999999999999999999", "10000000000000000000", "", errOrderPriceOverflow},
+		{"zero buy volume", "5", "0", "", errOrderZeroBuyVolume},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, err := orderPrice(tt.sell, tt.buy)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("orderPrice(%s, %s) error = %v, want %v", tt.sell, tt.buy, err, tt.err)
+			}
+			if price != tt.price {
+				t.Errorf("orderPrice(%s, %s) = %q, want %q", tt.sell, tt.buy, price, tt.price)
+			}
+		})
+	}
+}
+
+func TestOrderPriceInvalidVolume(t *testing.T) {
+	for _, volumes := range [][2]string{{"1.5", "1"}, {"1", "abc"}, {"", "1"}} {
+		if _, err := orderPrice(volumes[0], volumes[1]); err == nil {
+			t.Errorf("orderPrice(%q, %q) returned no error", volumes[0], volumes[1])
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"sync"
+)
+
+const (
+	// PolicyStrict fails the upload on the first data error
+	PolicyStrict = "strict"
+	// PolicyLenient continues the upload unless a stage exceeds its skip threshold
+	PolicyLenient = "lenient"
+)
+
+// checkStage applies error policy to what has been reported for the stage so far,
+// the error wraps cause
+func (egu *ExplorerGenesisUploader) checkStage(stage string, cause error) error {
+	skipped, altered, failed, issues, errs := egu.report.counters(stage)
+
+	if egu.env.ErrorPolicy == PolicyStrict && issues+errs > 0 {
+		return fmt.Errorf("%w: %s: data error under strict policy: %d issues, %d errors", cause, stage, issues, errs)
+	}
+
+	threshold, ok := egu.env.SkipThresholds[stage]
+	if ok && uint64(skipped+altered+failed) > threshold {
+		return fmt.Errorf("%w: %s: %d rows skipped, altered or failed, threshold is %d", cause, stage, skipped+altered+failed, threshold)
+	}
+
+	return nil
+}
+
+// skip records an entity which has not been uploaded
+func (egu *ExplorerGenesisUploader) skip(stage, entity string, id interface{}, reason string) error {
+	egu.report.skip(stage, entity, id, reason)
+	egu.metrics.rows.WithLabelValues(stage, "skipped").Inc()
+	return egu.checkStage(stage, ErrValidationFailed)
+}
+
+// warn records an entity which has been uploaded with incomplete data
+func (egu *ExplorerGenesisUploader) warn(stage, entity string, id interface{}, reason string) error {
+	egu.report.warn(stage, entity, id, reason)
+	return egu.checkStage(stage, ErrValidationFailed)
+}
+
+// alter records an entity which has been uploaded with a value changed to fit DB,
+// it is a data error counted against the skip threshold like a skipped row
+func (egu *ExplorerGenesisUploader) alter(stage, entity string, id interface{}, reason string) error {
+	egu.logger.WithFields(logrus.Fields{"stage": stage, "entity": entity, "id": id}).Error(reason)
+	egu.report.alter(stage, entity, id, reason)
+	egu.metrics.rows.WithLabelValues(stage, "altered").Inc()
+	return egu.checkStage(stage, ErrValidationFailed)
+}
+
+// fail records rows which could not be saved, a write error fails the upload under any policy
+func (egu *ExplorerGenesisUploader) fail(stage string, rows int, err error) error {
+	egu.logger.WithField("stage", stage).Error(err)
+	egu.report.fail(stage, rows, err)
+	egu.metrics.rows.WithLabelValues(stage, "failed").Add(float64(rows))
+	egu.metrics.errors.WithLabelValues(stage).Inc()
+	return fmt.Errorf("%w: %s: %d rows: %s", ErrDBWrite, stage, rows, err)
+}
+
+// firstError keeps the first error reported by concurrent workers
+type firstError struct {
+	once sync.Once
+	err  error
+}
+
+func (f *firstError) set(err error) {
+	if err != nil {
+		f.once.Do(func() {
+			f.err = err
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package core
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// ProgressAuto redraws a progress line when stderr is a terminal and logs go elsewhere,
+	// logs progress otherwise
+	ProgressAuto = "auto"
+	// ProgressTTY redraws a progress line on stderr
+	ProgressTTY = "tty"
+	// ProgressLog logs progress every ProgressInterval seconds
+	ProgressLog = "log"
+	// ProgressOff disables progress output
+	ProgressOff = "off"
+
+	defaultProgressInterval = 10
+	ttyRedrawInterval       = time.Second
+)
+
+// ProgressSnapshot is a state of the stage phase being processed
+type ProgressSnapshot struct {
+	Stage         string  `json:"stage"`
+	Phase         string  `json:"phase"`
+	Processed     int64   `json:"processed"`
+	Total         int64   `json:"total"`
+	RowsPerSecond float64 `json:"rows_per_second"`
+	ETASeconds    float64 `json:"eta_seconds"`
+}
+
+// String formats snapshot as a single progress line
+func (s ProgressSnapshot) String() string {
+	percent := 100.0
+	if s.Total > 0 {
+		percent = float64(s.Processed) * 100 / float64(s.Total)
+	}
+	eta := "unknown"
+	if s.RowsPerSecond > 0 {
+		eta = (time.Duration(s.ETASeconds) * time.Second).String()
+	}
+	return fmt.Sprintf("%s %s: %d/%d rows (%.1f%%), %.0f rows/s, ETA %s",
+		s.Stage, s.Phase, s.Processed, s.Total, percent, s.RowsPerSecond, eta)
+}
+
+// progress counts processed rows of one stage phase, e.g. saving balances
+type progress struct {
+	stage     string
+	phase     string
+	total     int64
+	processed int64
+	start     time.Time
+	done      chan struct{}
+	stopped   sync.WaitGroup
+}
+
+// add marks rows as processed, safe for concurrent chunk workers
+func (p *progress) add(rows int) {
+	atomic.AddInt64(&p.processed, int64(rows))
+}
+
+func (p *progress) snapshot() ProgressSnapshot {
+	s := ProgressSnapshot{
+		Stage:     p.stage,
+		Phase:     p.phase,
+		Processed: atomic.LoadInt64(&p.processed),
+		Total:     p.total,
+	}
+	elapsed := time.Since(p.start).Seconds()
+	if elapsed > 0 && s.Processed > 0 {
+		s.RowsPerSecond = float64(s.Processed) / elapsed
+		if remaining := s.Total - s.Processed; remaining > 0 {
+			s.ETASeconds = float64(remaining) / s.RowsPerSecond
+		}
+	}
+	return s
+}
+
+// progressTracker keeps the stage phase currently processed
+type progressTracker struct {
+	mu       sync.Mutex
+	current  *progress
+	mode     string
+	interval time.Duration
+	logger   *logrus.Entry
+}
+
+// newProgressTracker falls back to logging progress when logs go to a terminal,
+// a redrawn line would be torn apart by log lines
+func newProgressTracker(mode string, intervalSeconds uint64, logger *logrus.Entry) *progressTracker {
+	logsToTerminal := false
+	if out, ok := logger.Logger.Out.(*os.File); ok {
+		logsToTerminal = isTerminal(out)
+	}
+	switch mode {
+	case "", ProgressAuto:
+		mode = ProgressLog
+		if isTerminal(os.Stderr) && !logsToTerminal {
+			mode = ProgressTTY
+		}
+	case ProgressTTY:
+		if logsToTerminal {
+			logger.Warn("progress line is disabled while logs are written to a terminal, progress is logged")
+			mode = ProgressLog
+		}
+	}
+	if intervalSeconds == 0 {
+		intervalSeconds = defaultProgressInterval
+	}
+	return &progressTracker{
+		mode:     mode,
+		interval: time.Duration(intervalSeconds) * time.Second,
+		logger:   logger,
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// track starts tracking of the stage phase, the returned progress must be stopped
+func (egu *ExplorerGenesisUploader) track(stage, phase string, total int) *progress {
+	p := &progress{
+		stage: stage,
+		phase: phase,
+		total: int64(total),
+		start: time.Now(),
+		done:  make(chan struct{}),
+	}
+
+	t := egu.progress
+	t.mu.Lock()
+	t.current = p
+	t.mu.Unlock()
+
+	if t.mode == ProgressOff {
+		return p
+	}
+
+	interval := t.interval
+	if t.mode == ProgressTTY {
+		interval = ttyRedrawInterval
+	}
+
+	p.stopped.Add(1)
+	go func() {
+		defer p.stopped.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.print(p.snapshot())
+			case <-p.done:
+				if t.mode == ProgressTTY {
+					t.print(p.snapshot())
+					fmt.Fprintln(os.Stderr)
+				}
+				return
+			}
+		}
+	}()
+
+	return p
+}
+
+// untrack finishes tracking of the stage phase
+func (egu *ExplorerGenesisUploader) untrack(p *progress) {
+	close(p.done)
+	p.stopped.Wait()
+
+	t := egu.progress
+	t.mu.Lock()
+	if t.current == p {
+		t.current = nil
+	}
+	t.mu.Unlock()
+}
+
+// advance marks rows of the stage phase being processed as done
+func (egu *ExplorerGenesisUploader) advance(rows int) {
+	t := egu.progress
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current != nil {
+		t.current.add(rows)
+	}
+}
+
+// currentProgress returns the stage phase being processed, false between stages
+func (egu *ExplorerGenesisUploader) currentProgress() (ProgressSnapshot, bool) {
+	t := egu.progress
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current == nil {
+		return ProgressSnapshot{}, false
+	}
+	return t.current.snapshot(), true
+}
+
+func (t *progressTracker) print(s ProgressSnapshot) {
+	if t.mode == ProgressTTY {
+		fmt.Fprintf(os.Stderr, "\r\033[K%s", s)
+		return
+	}
+	t.logger.WithFields(logrus.Fields{
+		"stage":           s.Stage,
+		"phase":           s.Phase,
+		"processed":       s.Processed,
+		"total":           s.Total,
+		"rows_per_second": fmt.Sprintf("%.0f", s.RowsPerSecond),
+		"eta":             (time.Duration(s.ETASeconds) * time.Second).String(),
+	}).Info("Progress")
+}
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/MinterTeam/explorer-genesis-uploader/validation"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+// Issue describes a genesis entity that could not be uploaded as is
+type Issue struct {
+	Entity  string `json:"entity"`
+	ID      string `json:"id"`
+	Reason  string `json:"reason"`
+	Skipped bool   `json:"skipped"`
+}
+
+// StageReport is a summary of extracting and saving one kind of entities
+type StageReport struct {
+	Name           string   `json:"name"`
+	Extracted      int      `json:"extracted"`
+	Saved          int      `json:"saved"`
+	Skipped        int      `json:"skipped"`
+	Altered        int      `json:"altered"`
+	Failed         int      `json:"failed"`
+	Issues         []Issue  `json:"issues"`
+	Errors         []string `json:"errors"`
+	ExtractSeconds float64  `json:"extract_seconds"`
+	SaveSeconds    float64  `json:"save_seconds"`
+}
+
+// Report is a machine-readable summary of an upload run
+type Report struct {
+	mu sync.Mutex
+
+	Source        string                 `json:"source"`
+	ChainID       string                 `json:"chain_id"`
+	InitialHeight uint64                 `json:"initial_height"`
+	StartedAt     time.Time              `json:"started_at"`
+	FinishedAt    time.Time              `json:"finished_at"`
+	Seconds       float64                `json:"seconds"`
+	Success       bool                   `json:"success"`
+	Error         string                 `json:"error,omitempty"`
+	Violations    []validation.Violation `json:"violations"`
+	Stages        []*StageReport         `json:"stages"`
+}
+
+func newReport() *Report {
+	return &Report{StartedAt: time.Now()}
+}
+
+// stage returns report of the stage, must be called under lock
+func (r *Report) stage(name string) *StageReport {
+	for _, s := range r.Stages {
+		if s.Name == name {
+			return s
+		}
+	}
+	s := &StageReport{Name: name}
+	r.Stages = append(r.Stages, s)
+	return s
+}
+
+func (r *Report) issue(stage, entity string, id interface{}, reason string, skipped bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.stage(stage)
+	s.Issues = append(s.Issues, Issue{
+		Entity:  entity,
+		ID:      fmt.Sprint(id),
+		Reason:  reason,
+		Skipped: skipped,
+	})
+	if skipped {
+		s.Skipped++
+	}
+}
+
+// skip records an entity which has not been uploaded
+func (r *Report) skip(stage, entity string, id interface{}, reason string) {
+	r.issue(stage, entity, id, reason, true)
+}
+
+// warn records an entity which has been uploaded with incomplete data
+func (r *Report) warn(stage, entity string, id interface{}, reason string) {
+	r.issue(stage, entity, id, reason, false)
+}
+
+// alter records an entity which has been uploaded with a value changed to fit DB
+func (r *Report) alter(stage, entity string, id interface{}, reason string) {
+	r.issue(stage, entity, id, reason, false)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stage(stage).Altered++
+}
+
+// fail records rows which could not be saved
+func (r *Report) fail(stage string, rows int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.stage(stage)
+	s.Failed += rows
+	s.Errors = append(s.Errors, err.Error())
+}
+
+func (r *Report) counters(stage string) (skipped, altered, failed, issues, errs int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.stage(stage)
+	return s.Skipped, s.Altered, s.Failed, len(s.Issues), len(s.Errors)
+}
+
+func (r *Report) extracted(stage string, count int, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.stage(stage)
+	s.Extracted = count
+	s.ExtractSeconds = duration.Seconds()
+}
+
+func (r *Report) saved(stage string, count int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stage(stage).Saved += count
+}
+
+func (r *Report) saveDuration(stage string, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stage(stage).SaveSeconds = duration.Seconds()
+}
+
+func (r *Report) finish(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.FinishedAt = time.Now()
+	r.Seconds = r.FinishedAt.Sub(r.StartedAt).Seconds()
+	r.Success = err == nil
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+// status returns state of the run and stage counters without issues and errors
+func (r *Report) status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := Status{
+		State:     StateRunning,
+		StartedAt: r.StartedAt,
+		Seconds:   time.Since(r.StartedAt).Seconds(),
+		Stages:    make([]StageReport, len(r.Stages)),
+	}
+	if !r.FinishedAt.IsZero() {
+		status.Seconds = r.Seconds
+		status.State = StateSucceeded
+		if !r.Success {
+			status.State = StateFailed
+			status.Error = r.Error
+		}
+	}
+	for i, s := range r.Stages {
+		status.Stages[i] = *s
+		status.Stages[i].Issues = nil
+		status.Stages[i].Errors = nil
+	}
+	return status
+}
+
+func (r *Report) WriteFile(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/go-pg/pg/v10/orm"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+// Formats of exported rows
+const (
+	RowsFormatCSV    = "csv"
+	RowsFormatNDJSON = "ndjson"
+)
+
+// ExportRows extracts genesis without DB and writes rows of every stage into dir,
+// one <stage>.csv or <stage>.ndjson file per stage with DB column names
+func (egu *ExplorerGenesisUploader) ExportRows(dir, format string) error {
+	if format != RowsFormatCSV && format != RowsFormatNDJSON {
+		return fmt.Errorf("unknown format %q, expected %s or %s", format, RowsFormatCSV, RowsFormatNDJSON)
+	}
+
+	start := time.Now()
+	egu.logger.Info("Getting genesis data...")
+	genesis, err := egu.loadGenesis()
+	if err != nil {
+		egu.logger.Error(err)
+		return err
+	}
+	if err := egu.validate(genesis); err != nil {
+		return err
+	}
+
+	e, err := egu.extractAll(genesis)
+	if err != nil {
+		egu.logger.Error(err)
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, s := range e.stages() {
+		path := filepath.Join(dir, s.stage+"."+format)
+		if err := writeRowsFile(path, format, s.rows); err != nil {
+			egu.logger.Error(err)
+			return err
+		}
+	}
+	egu.logger.Info(fmt.Sprintf("Rows have been written to %s. Processing time %s", dir, time.Since(start)))
+	return nil
+}
+
+func writeRowsFile(path, format string, rows interface{}) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if format == RowsFormatCSV {
+		err = writeCSV(w, rows)
+	} else {
+		err = writeNDJSON(w, rows)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// columnDefault is a value go-pg inserts as DEFAULT, a zero value of a nullable column
+type columnDefault struct{}
+
+// rowColumns returns go-pg table of the rows slice and an iterator over its values
+func rowColumns(rows interface{}) (*orm.Table, func(func(values []interface{}) error) error) {
+	slice := reflect.ValueOf(rows)
+	typ := slice.Type().Elem()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	table := orm.GetTable(typ)
+
+	each := func(fn func(values []interface{}) error) error {
+		values := make([]interface{}, len(table.Fields))
+		for i := 0; i < slice.Len(); i++ {
+			strct := reflect.Indirect(slice.Index(i))
+			for j, f := range table.Fields {
+				values[j] = nil
+				if f.NullZero() && f.HasZeroValue(strct) {
+					values[j] = columnDefault{}
+					continue
+				}
+				if v := reflect.Indirect(f.Value(strct)); v.IsValid() {
+					values[j] = v.Interface()
+				}
+			}
+			if err := fn(values); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return table, each
+}
+
+func writeCSV(w io.Writer, rows interface{}) error {
+	table, each := rowColumns(rows)
+	cw := csv.NewWriter(w)
+	record := make([]string, len(table.Fields))
+	for i, f := range table.Fields {
+		record[i] = f.SQLName
+	}
+	if err := cw.Write(record); err != nil {
+		return err
+	}
+	err := each(func(values []interface{}) error {
+		for i, v := range values {
+			switch v.(type) {
+			case nil, columnDefault:
+				record[i] = ""
+			default:
+				record[i] = fmt.Sprint(v)
+			}
+		}
+		return cw.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeNDJSON(w io.Writer, rows interface{}) error {
+	table, each := rowColumns(rows)
+	return each(func(values []interface{}) error {
+		// keys are written in column order, so map is not used
+		line := []byte{'{'}
+		for i, v := range values {
+			if i > 0 {
+				line = append(line, ',')
+			}
+			if _, ok := v.(columnDefault); ok {
+				v = nil
+			}
+			key, _ := json.Marshal(table.Fields[i].SQLName)
+			value, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			line = append(append(append(line, key...), ':'), value...)
+		}
+		line = append(line, '}', '\n')
+		_, err := w.Write(line)
+		return err
+	})
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/MinterTeam/explorer-genesis-uploader/domain"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// copyEscaper escapes values of COPY text format
+var copyEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// ExportSQL extracts genesis without DB and writes a psql script inserting the same rows
+// with the same ids as an upload into an empty DB, sequences are set as the upload leaves them
+func (egu *ExplorerGenesisUploader) ExportSQL(path string) error {
+	start := time.Now()
+	egu.logger.Info("Getting genesis data...")
+	genesis, err := egu.loadGenesis()
+	if err != nil {
+		egu.logger.Error(err)
+		return err
+	}
+	if err := egu.validate(genesis); err != nil {
+		return err
+	}
+
+	e, err := egu.extractAll(genesis)
+	if err != nil {
+		egu.logger.Error(err)
+		return err
+	}
+
+	out := os.Stdout
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+
+	fmt.Fprintf(w, "-- Genesis %s at height %d from %s\n", genesis.ChainID, genesis.InitialHeight, egu.source())
+	fmt.Fprintf(w, "-- Generated by explorer genesis uploader %s, apply with: psql -v ON_ERROR_STOP=1 -f <file>\n\n", Version)
+	fmt.Fprintln(w, "BEGIN;")
+
+	uploads := []*domain.GenesisUpload{{
+		ChainID:       genesis.ChainID,
+		InitialHeight: genesis.InitialHeight,
+		Source:        egu.source(),
+		StartedAt:     egu.report.StartedAt,
+	}}
+	if err := writeCopy(w, uploads); err != nil {
+		return err
+	}
+	for _, t := range e.tables() {
+		if err := writeCopy(w, t.rows); err != nil {
+			return err
+		}
+	}
+
+	// rows are copied with their ids, so sequences are moved past them as the upload leaves them
+	fmt.Fprintln(w)
+	for _, t := range e.tables() {
+		if !t.sequenced {
+			continue
+		}
+		table, _ := rowColumns(t.rows)
+		seq := fmt.Sprintf("pg_get_serial_sequence('%s', 'id')", table.SQLName)
+		if last := reflect.ValueOf(t.rows).Len(); last == 0 {
+			fmt.Fprintf(w, "SELECT setval(%s, 1, false);\n", seq)
+		} else {
+			fmt.Fprintf(w, "SELECT setval(%s, %d);\n", seq, last)
+		}
+	}
+	fmt.Fprintln(w, "\nCOMMIT;")
+
+	if err := w.Flush(); err != nil {
+		egu.logger.Error(err)
+		return err
+	}
+	egu.logger.Info(fmt.Sprintf("SQL dump has been written. Processing time %s", time.Since(start)))
+	return nil
+}
+
+// writeCopy writes rows as COPY blocks, columns go-pg inserts as DEFAULT are left out
+// of the column list, so rows are grouped by the set of such columns
+func writeCopy(w io.Writer, rows interface{}) error {
+	table, each := rowColumns(rows)
+
+	var groups []string
+	lines := make(map[string][]string)
+	err := each(func(values []interface{}) error {
+		var columns, fields []string
+		for i, v := range values {
+			switch v := v.(type) {
+			case columnDefault:
+				continue
+			case nil:
+				fields = append(fields, `\N`)
+			case time.Time:
+				fields = append(fields, v.Format(time.RFC3339Nano))
+			default:
+				fields = append(fields, copyEscaper.Replace(fmt.Sprint(v)))
+			}
+			columns = append(columns, string(table.Fields[i].Column))
+		}
+		key := strings.Join(columns, ", ")
+		if _, ok := lines[key]; !ok {
+			groups = append(groups, key)
+		}
+		lines[key] = append(lines[key], strings.Join(fields, "\t"))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, columns := range groups {
+		fmt.Fprintf(w, "\nCOPY %s (%s) FROM stdin;\n", table.SQLName, columns)
+		for _, line := range lines[columns] {
+			fmt.Fprintln(w, line)
+		}
+		fmt.Fprintln(w, `\.`)
+	}
+	return nil
+}
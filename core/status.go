@@ -0,0 +1,134 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// States of the upload run
+const (
+	StateRunning   = "running"
+	StateSucceeded = "succeeded"
+	StateFailed    = "failed"
+)
+
+// Status is a state of the upload run served on /progress
+type Status struct {
+	State     string            `json:"state"`
+	Error     string            `json:"error,omitempty"`
+	StartedAt time.Time         `json:"started_at"`
+	Seconds   float64           `json:"seconds"`
+	Current   *ProgressSnapshot `json:"current,omitempty"`
+	Stages    []StageReport     `json:"stages"`
+}
+
+// Status returns the current state of the upload run
+func (egu *ExplorerGenesisUploader) Status() Status {
+	status := egu.report.status()
+	if current, ok := egu.currentProgress(); ok {
+		status.Current = &current
+	}
+	return status
+}
+
+func (egu *ExplorerGenesisUploader) healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "ok")
+}
+
+// readyz reports ready once genesis has been uploaded successfully
+func (egu *ExplorerGenesisUploader) readyz(w http.ResponseWriter, r *http.Request) {
+	state := egu.report.status().State
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if state != StateSucceeded {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	fmt.Fprintln(w, state)
+}
+
+func (egu *ExplorerGenesisUploader) progressz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(egu.Status()); err != nil {
+		egu.logger.Error(err)
+	}
+}
+
+// serveHTTP starts configured metrics and status servers for the duration of the run,
+// both are served by one server when their addresses match. The returned func stops them,
+// Do calls it after linger.
+func (egu *ExplorerGenesisUploader) serveHTTP() (func(), error) {
+	muxes := make(map[string]*http.ServeMux)
+	mux := func(addr string) *http.ServeMux {
+		if muxes[addr] == nil {
+			muxes[addr] = http.NewServeMux()
+		}
+		return muxes[addr]
+	}
+
+	if egu.env.MetricsAddr != "" {
+		mux(egu.env.MetricsAddr).Handle("/metrics", promhttp.HandlerFor(egu.metrics.registry, promhttp.HandlerOpts{}))
+	}
+	if egu.env.StatusAddr != "" {
+		m := mux(egu.env.StatusAddr)
+		m.HandleFunc("/healthz", egu.healthz)
+		m.HandleFunc("/readyz", egu.readyz)
+		m.HandleFunc("/progress", egu.progressz)
+	}
+
+	var servers []*http.Server
+	stop := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		for _, server := range servers {
+			if err := server.Shutdown(ctx); err != nil {
+				egu.logger.Error(err)
+			}
+		}
+	}
+
+	for addr, m := range muxes {
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			stop()
+			return nil, err
+		}
+		server := &http.Server{Handler: m}
+		servers = append(servers, server)
+		go func() {
+			if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+				egu.logger.Error(err)
+			}
+		}()
+		egu.logger.Info(fmt.Sprintf("Serving HTTP on %s", listener.Addr()))
+	}
+
+	return stop, nil
+}
+
+// linger keeps HTTP servers up for StatusLinger seconds after the run, so /readyz and /progress
+// report the final state to probes, SIGINT or SIGTERM stops it earlier
+func (egu *ExplorerGenesisUploader) linger() {
+	if egu.env.StatusLinger == 0 || (egu.env.StatusAddr == "" && egu.env.MetricsAddr == "") {
+		return
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	duration := time.Duration(egu.env.StatusLinger) * time.Second
+	egu.logger.Info(fmt.Sprintf("Run has finished, serving HTTP for %s or until SIGTERM", duration))
+	select {
+	case <-time.After(duration):
+	case s := <-signals:
+		egu.logger.Info(fmt.Sprintf("Received %s, stopping HTTP", s))
+	}
+}
@@ -0,0 +1,421 @@
+package core
+
+import (
+	"fmt"
+	"github.com/MinterTeam/explorer-genesis-uploader/domain"
+	"github.com/MinterTeam/explorer-genesis-uploader/helpers"
+	"github.com/sirupsen/logrus"
+	"math/big"
+	"time"
+)
+
+// Discrepancy is a mismatch between genesis and data stored in DB
+type Discrepancy struct {
+	Check    string `json:"check"`
+	Key      string `json:"key"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+type verifier struct {
+	discrepancies []Discrepancy
+	// merged is set when genesis has been layered onto existing data,
+	// then only rows of genesis are compared
+	merged bool
+}
+
+func (v *verifier) count(table string, expected int, actual int) {
+	if expected != actual {
+		v.discrepancies = append(v.discrepancies, Discrepancy{
+			Check:    "count",
+			Key:      table,
+			Expected: fmt.Sprint(expected),
+			Actual:   fmt.Sprint(actual),
+		})
+	}
+}
+
+// amount compares numbers, empty actual means the row is missing in DB
+func (v *verifier) amount(check, key string, expected *big.Int, actual string) {
+	if expected == nil {
+		expected = new(big.Int)
+	}
+	got, ok := new(big.Int).SetString(actual, 10)
+	if !ok {
+		got = new(big.Int)
+	}
+	if expected.Cmp(got) != 0 || (actual == "" && expected.Sign() != 0) {
+		if actual == "" {
+			actual = "missing"
+		}
+		v.discrepancies = append(v.discrepancies, Discrepancy{
+			Check:    check,
+			Key:      key,
+			Expected: expected.String(),
+			Actual:   actual,
+		})
+	}
+}
+
+func addAmount(sums map[string]*big.Int, key, value string) {
+	v, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return
+	}
+	if sums[key] == nil {
+		sums[key] = new(big.Int)
+	}
+	sums[key].Add(sums[key], v)
+}
+
+// Verify reloads genesis and compares it with data stored in DB.
+// Returns every found discrepancy, an error means verification could not be done.
+// After a merge upload tables hold data besides genesis, so counts are not compared
+// and balances and stakes are compared row by row instead of sums.
+func (egu *ExplorerGenesisUploader) Verify() ([]Discrepancy, error) {
+	start := time.Now()
+	egu.logger.Info("Getting genesis data...")
+	genesis, err := egu.loadGenesis()
+	if err != nil {
+		egu.logger.Error(err)
+		return nil, err
+	}
+
+	v := new(verifier)
+	v.merged, err = egu.genesisUploadRepository.HasMerge()
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrDBUnavailable, err)
+		egu.logger.Error(err)
+		return nil, err
+	}
+	v.merged = v.merged || egu.env.Merge
+	if v.merged {
+		egu.logger.Info("Genesis has been merged into existing data, only its rows are compared")
+		coins, err := egu.extractCoins(genesis)
+		if err != nil {
+			egu.logger.Error(err)
+			return nil, err
+		}
+		if err := egu.matchCoins(coins); err != nil {
+			egu.logger.Error(err)
+			return nil, err
+		}
+		validators, err := egu.extractCandidates(genesis)
+		if err != nil {
+			egu.logger.Error(err)
+			return nil, err
+		}
+		if err := egu.matchValidators(validators); err != nil {
+			egu.logger.Error(err)
+			return nil, err
+		}
+		pools, err := egu.extractLiquidityPool(genesis, coins)
+		if err != nil {
+			egu.logger.Error(err)
+			return nil, err
+		}
+		if err := egu.matchPools(pools); err != nil {
+			egu.logger.Error(err)
+			return nil, err
+		}
+	}
+
+	checks := []struct {
+		name  string
+		check func(*verifier, *domain.Genesis) error
+	}{
+		{"counts", egu.verifyCounts},
+		{"balances", egu.verifyBalances},
+		{"stakes", egu.verifyStakes},
+		{"liquidity pools", egu.verifyLiquidityPools},
+		{"orders", egu.verifyOrders},
+	}
+	if v.merged {
+		checks = checks[1:]
+	}
+	for _, c := range checks {
+		egu.logger.Info(fmt.Sprintf("Verifying %s...", c.name))
+		if err := c.check(v, genesis); err != nil {
+			err = fmt.Errorf("%w: %s", ErrDBUnavailable, err)
+			egu.logger.Error(err)
+			return nil, err
+		}
+	}
+
+	for _, d := range v.discrepancies {
+		egu.logger.WithFields(logrus.Fields{
+			"check":    d.Check,
+			"key":      d.Key,
+			"expected": d.Expected,
+			"actual":   d.Actual,
+		}).Error("Discrepancy")
+	}
+	egu.logger.Info(fmt.Sprintf("Verification complete, %d discrepancies found. Processing time %s", len(v.discrepancies), time.Since(start)))
+
+	return v.discrepancies, nil
+}
+
+// verifyCounts compares row counts of tables with genesis, DB must hold only the upload,
+// expected addresses include the zero address the upload inserts
+func (egu *ExplorerGenesisUploader) verifyCounts(v *verifier, genesis *domain.Genesis) error {
+	addresses, err := egu.extractAddresses(genesis)
+	if err != nil {
+		return err
+	}
+	coins, err := egu.extractCoins(genesis)
+	if err != nil {
+		return err
+	}
+
+	// pools, their providers and orders are filtered the way the upload extracts them
+	liquidityPools, err := egu.extractLiquidityPool(genesis, coins)
+	if err != nil {
+		return err
+	}
+	addressLiquidityPools, err := egu.extractAddressLiquidityPools(genesis, liquidityPools)
+	if err != nil {
+		return err
+	}
+
+	var balances, stakes, orders int
+	for _, account := range genesis.AppState.Accounts {
+		balances += len(account.Balance)
+	}
+	for _, candidate := range genesis.AppState.Candidates {
+		stakes += len(candidate.Stakes)
+	}
+	extracted := make(map[uint64]struct{})
+	for _, pool := range liquidityPools {
+		extracted[pool.Id] = struct{}{}
+	}
+	for _, pool := range genesis.AppState.Pools {
+		if _, ok := extracted[pool.ID]; ok {
+			orders += len(pool.Orders)
+		}
+	}
+
+	counters := []struct {
+		table    string
+		expected int
+		actual   func() (int, error)
+	}{
+		{"addresses", len(addresses), egu.addressRepository.GetAddressesCount},
+		{"coins", len(coins), egu.coinRepository.GetAllCoinsCount},
+		{"validators", len(genesis.AppState.Candidates), egu.validatorRepository.GetValidatorsCount},
+		{"balances", balances, egu.balanceRepository.GetBalancesCount},
+		{"stakes", stakes, egu.validatorRepository.GetStakesCount},
+		{"unbonds", len(genesis.AppState.FrozenFunds), egu.validatorRepository.GetUnbondsCount},
+		{"address_liquidity_pools", len(addressLiquidityPools), egu.liquidityPoolRepository.GetAddressLiquidityPoolsCount},
+	}
+	for _, c := range counters {
+		actual, err := c.actual()
+		if err != nil {
+			return err
+		}
+		v.count(c.table, c.expected, actual)
+	}
+
+	pools, err := egu.liquidityPoolRepository.GetAll()
+	if err != nil {
+		return err
+	}
+	v.count("liquidity_pools", len(liquidityPools), len(pools))
+
+	list, err := egu.liquidityPoolRepository.GetAllOrders()
+	if err != nil {
+		return err
+	}
+	v.count("orders", orders, len(list))
+
+	return nil
+}
+
+func (egu *ExplorerGenesisUploader) verifyBalances(v *verifier, genesis *domain.Genesis) error {
+	if v.merged {
+		return egu.verifyMergedBalances(v, genesis)
+	}
+	expected := make(map[string]*big.Int)
+	for _, account := range genesis.AppState.Accounts {
+		for _, b := range account.Balance {
+			addAmount(expected, fmt.Sprint(b.Coin), b.Value)
+		}
+	}
+
+	sums, err := egu.balanceRepository.GetSumsByCoin()
+	if err != nil {
+		return err
+	}
+	actual := make(map[string]string, len(sums))
+	for coin, sum := range sums {
+		actual[fmt.Sprint(coin)] = sum
+	}
+
+	for coin, sum := range expected {
+		v.amount("balance_sum", "coin "+coin, sum, actual[coin])
+	}
+	for coin, sum := range actual {
+		if _, ok := expected[coin]; !ok {
+			v.amount("balance_sum", "coin "+coin, nil, sum)
+		}
+	}
+	return nil
+}
+
+func (egu *ExplorerGenesisUploader) verifyStakes(v *verifier, genesis *domain.Genesis) error {
+	if v.merged {
+		return egu.verifyMergedStakes(v, genesis)
+	}
+	expected := make(map[string]*big.Int)
+	for _, candidate := range genesis.AppState.Candidates {
+		for _, s := range candidate.Stakes {
+			addAmount(expected, fmt.Sprintf("validator %d coin %d", candidate.ID, s.Coin), s.Value)
+		}
+	}
+
+	sums, err := egu.validatorRepository.GetStakeSums()
+	if err != nil {
+		return err
+	}
+	actual := make(map[string]string)
+	for validator, coins := range sums {
+		for coin, sum := range coins {
+			actual[fmt.Sprintf("validator %d coin %d", validator, coin)] = sum
+		}
+	}
+
+	for key, sum := range expected {
+		v.amount("stake_sum", key, sum, actual[key])
+	}
+	for key, sum := range actual {
+		if _, ok := expected[key]; !ok {
+			v.amount("stake_sum", key, nil, sum)
+		}
+	}
+	return nil
+}
+
+func (egu *ExplorerGenesisUploader) verifyLiquidityPools(v *verifier, genesis *domain.Genesis) error {
+	pools, err := egu.liquidityPoolRepository.GetAll()
+	if err != nil {
+		return err
+	}
+	stored := make(map[uint64]*domain.LiquidityPool, len(pools))
+	for _, p := range pools {
+		stored[p.Id] = p
+	}
+
+	for _, p := range genesis.AppState.Pools {
+		var reserve0, reserve1 string
+		if lp, ok := stored[egu.poolId(p.ID)]; ok {
+			reserve0, reserve1 = lp.FirstCoinVolume, lp.SecondCoinVolume
+		}
+		expected0, _ := new(big.Int).SetString(p.Reserve0, 10)
+		expected1, _ := new(big.Int).SetString(p.Reserve1, 10)
+		v.amount("pool_reserve", fmt.Sprintf("pool %d coin %d", p.ID, p.Coin0), expected0, reserve0)
+		v.amount("pool_reserve", fmt.Sprintf("pool %d coin %d", p.ID, p.Coin1), expected1, reserve1)
+	}
+	return nil
+}
+
+func (egu *ExplorerGenesisUploader) verifyOrders(v *verifier, genesis *domain.Genesis) error {
+	orders, err := egu.liquidityPoolRepository.GetAllOrders()
+	if err != nil {
+		return err
+	}
+	stored := make(map[uint64]domain.Order, len(orders))
+	for _, o := range orders {
+		stored[o.Id] = o
+	}
+
+	for _, p := range genesis.AppState.Pools {
+		for _, o := range p.Orders {
+			var volume0, volume1 string
+			if order, ok := stored[o.Id]; ok {
+				if o.IsSale {
+					volume0, volume1 = order.CoinSellVolume, order.CoinBuyVolume
+				} else {
+					volume0, volume1 = order.CoinBuyVolume, order.CoinSellVolume
+				}
+			}
+			expected0, _ := new(big.Int).SetString(o.Volume0, 10)
+			expected1, _ := new(big.Int).SetString(o.Volume1, 10)
+			v.amount("order_volume", fmt.Sprintf("order %d coin %d", o.Id, p.Coin0), expected0, volume0)
+			v.amount("order_volume", fmt.Sprintf("order %d coin %d", o.Id, p.Coin1), expected1, volume1)
+		}
+	}
+	return nil
+}
+
+// addressIds maps stored addresses without prefix to their ids
+func (egu *ExplorerGenesisUploader) addressIds() (map[string]uint64, error) {
+	addresses, err := egu.addressRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]uint64, len(addresses))
+	for _, a := range addresses {
+		ids[a.Address] = a.ID
+	}
+	return ids, nil
+}
+
+// verifyMergedBalances compares every balance of genesis with the stored one
+func (egu *ExplorerGenesisUploader) verifyMergedBalances(v *verifier, genesis *domain.Genesis) error {
+	addressIds, err := egu.addressIds()
+	if err != nil {
+		return err
+	}
+	balances, err := egu.balanceRepository.GetAll()
+	if err != nil {
+		return err
+	}
+	stored := make(map[[2]uint64]string, len(balances))
+	for _, b := range balances {
+		stored[[2]uint64{b.AddressID, b.CoinID}] = b.Value
+	}
+
+	for _, account := range genesis.AppState.Accounts {
+		addressId := addressIds[helpers.RemovePrefix(account.Address)]
+		for _, b := range account.Balance {
+			expected, _ := new(big.Int).SetString(b.Value, 10)
+			actual := stored[[2]uint64{addressId, egu.coinId(b.Coin)}]
+			v.amount("balance", fmt.Sprintf("%s coin %d", account.Address, b.Coin), expected, actual)
+		}
+	}
+	return nil
+}
+
+// verifyMergedStakes compares sums of stakes of genesis owners with the stored ones
+func (egu *ExplorerGenesisUploader) verifyMergedStakes(v *verifier, genesis *domain.Genesis) error {
+	addressIds, err := egu.addressIds()
+	if err != nil {
+		return err
+	}
+	stakes, err := egu.validatorRepository.GetAllStakes()
+	if err != nil {
+		return err
+	}
+	stored := make(map[string]*big.Int)
+	for _, s := range stakes {
+		addAmount(stored, fmt.Sprintf("%d %d %d", s.ValidatorID, s.OwnerAddressID, s.CoinID), s.Value)
+	}
+
+	expected := make(map[string]*big.Int)
+	keys := make(map[string]string)
+	for _, candidate := range genesis.AppState.Candidates {
+		for _, s := range candidate.Stakes {
+			ownerId := addressIds[helpers.RemovePrefix(s.Owner)]
+			key := fmt.Sprintf("%d %d %d", egu.validatorId(candidate.ID), ownerId, egu.coinId(s.Coin))
+			addAmount(expected, key, s.Value)
+			keys[key] = fmt.Sprintf("validator %d owner %s coin %d", candidate.ID, s.Owner, s.Coin)
+		}
+	}
+	for key, sum := range expected {
+		actual := ""
+		if stored[key] != nil {
+			actual = stored[key].String()
+		}
+		v.amount("stake", keys[key], sum, actual)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// WipeTables are the tables filled by upload in FK-safe order, referencing tables first.
+// Rows are deleted instead of truncating, as explorer tables reference them.
+var WipeTables = []string{
+	"orders",
+	"address_liquidity_pools",
+	"liquidity_pools",
+	"unbonds",
+	"stakes",
+	"balances",
+	"validator_public_keys",
+	"validators",
+	"coins",
+	"addresses",
+	"genesis_uploads",
+}
+
+// Wipe removes uploaded data from DB, so the upload can be run again.
+// chainID must match the chain of every recorded upload. Tables are wiped entirely,
+// so DB where genesis has been merged into existing data is not wiped.
+func (egu *ExplorerGenesisUploader) Wipe(chainID string) error {
+	start := time.Now()
+
+	if err := egu.checkSchema(); err != nil {
+		egu.logger.Error(err)
+		return err
+	}
+
+	chainIDs, err := egu.genesisUploadRepository.GetChainIDs()
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrDBUnavailable, err)
+		egu.logger.Error(err)
+		return err
+	}
+	if len(chainIDs) == 0 {
+		err = fmt.Errorf("%w: no recorded upload, chain id cannot be checked", ErrWipeRefused)
+		egu.logger.Error(err)
+		return err
+	}
+	for _, id := range chainIDs {
+		if id != chainID {
+			err = fmt.Errorf("%w: DB holds chain %s, not %s", ErrWipeRefused, id, chainID)
+			egu.logger.Error(err)
+			return err
+		}
+	}
+
+	merged, err := egu.genesisUploadRepository.HasMerge()
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrDBUnavailable, err)
+		egu.logger.Error(err)
+		return err
+	}
+	if merged {
+		err = fmt.Errorf("%w: genesis has been merged into existing data, which wipe would delete too", ErrWipeRefused)
+		egu.logger.Error(err)
+		return err
+	}
+
+	egu.logger.Info(fmt.Sprintf("Wiping %s...", chainID))
+	deleted, err := egu.schemaRepository.Wipe(WipeTables)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrDBWrite, err)
+		egu.logger.Error(err)
+		return err
+	}
+	for _, table := range WipeTables {
+		egu.logger.WithFields(logrus.Fields{
+			"table": table,
+			"rows":  deleted[table],
+		}).Info("Table has been wiped")
+	}
+	egu.logger.Info(fmt.Sprintf("Wipe complete. Processing time %s", time.Since(start)))
+	return nil
+}
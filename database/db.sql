@@ -641,6 +641,93 @@ CREATE TABLE public.index_transaction_by_address
     transaction_id bigint NOT NULL
 );
 
+--
+-- Name: genesis_uploads; Type: TABLE; Schema: public; Owner: minter
+--
+
+CREATE TABLE public.genesis_uploads
+(
+    chain_id       varchar(64)              NOT NULL,
+    initial_height bigint                   NOT NULL,
+    source         varchar                  NOT NULL,
+    merge          boolean                  NOT NULL DEFAULT false,
+    started_at     timestamp with time zone NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE public.genesis_uploads IS 'Genesis uploads, wipe command checks chain id against them';
+COMMENT ON COLUMN public.genesis_uploads.merge IS 'Genesis has been layered onto existing data';
+
+
+--
+-- Name: unbonds; Type: TABLE; Schema: public; Owner: minter
+--
+
+CREATE TABLE public.unbonds
+(
+    block_id        integer        NOT NULL,
+    unlock_block_id bigint,
+    address_id      bigint         NOT NULL,
+    coin_id         integer        NOT NULL,
+    validator_id    integer,
+    type            smallint       NOT NULL DEFAULT 1,
+    value           numeric(70, 0) NOT NULL
+);
+
+COMMENT ON COLUMN public.unbonds.block_id IS 'Block id, at which the funds are unfrozen, same as unlock_block_id';
+COMMENT ON COLUMN public.unbonds.type IS '1 - unbond, 2 - move stake, 3 - lock';
+COMMENT ON COLUMN public.unbonds.unlock_block_id IS 'Block id, at which the funds are unfrozen';
+
+
+--
+-- Name: liquidity_pools; Type: TABLE; Schema: public; Owner: minter
+--
+
+CREATE TABLE public.liquidity_pools
+(
+    id                  bigint          NOT NULL,
+    token_id            bigint          NOT NULL,
+    first_coin_id       integer         NOT NULL,
+    second_coin_id      integer         NOT NULL,
+    first_coin_volume   numeric(100, 0) NOT NULL,
+    second_coin_volume  numeric(100, 0) NOT NULL,
+    liquidity           numeric(100, 0) NOT NULL,
+    liquidity_bip       numeric(100, 0),
+    updated_at_block_id bigint          NOT NULL
+);
+
+
+--
+-- Name: address_liquidity_pools; Type: TABLE; Schema: public; Owner: minter
+--
+
+CREATE TABLE public.address_liquidity_pools
+(
+    liquidity_pool_id bigint          NOT NULL,
+    address_id        bigint          NOT NULL,
+    liquidity         numeric(100, 0) NOT NULL
+);
+
+
+--
+-- Name: orders; Type: TABLE; Schema: public; Owner: minter
+--
+
+CREATE TABLE public.orders
+(
+    id                bigint          NOT NULL,
+    address_id        bigint          NOT NULL,
+    liquidity_pool_id bigint          NOT NULL,
+    price             numeric(25, 18),
+    coin_sell_id      integer         NOT NULL,
+    coin_sell_volume  numeric(100, 0) NOT NULL,
+    coin_buy_id       integer         NOT NULL,
+    coin_buy_volume   numeric(100, 0) NOT NULL,
+    created_at_block  bigint          NOT NULL,
+    status            smallint        NOT NULL
+);
+
+COMMENT ON COLUMN public.orders.status IS '1 - new, 2 - active, 3 - partially filled, 4 - filled, 5 - canceled, 6 - expired';
+
 --
 -- Name: id; Type: DEFAULT; Schema: public; Owner: minter
 --
@@ -1507,6 +1594,65 @@ ALTER TABLE ONLY public.index_transaction_by_address
     ADD CONSTRAINT index_transaction_by_address_transactions_id_fk FOREIGN KEY (transaction_id) REFERENCES public.transactions (id);
 
 
+--
+-- Name: liquidity_pools liquidity_pools_pkey; Type: CONSTRAINT; Schema: public; Owner: minter
+--
+
+ALTER TABLE ONLY public.liquidity_pools
+    ADD CONSTRAINT liquidity_pools_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.address_liquidity_pools
+    ADD CONSTRAINT address_liquidity_pools_pkey PRIMARY KEY (liquidity_pool_id, address_id);
+
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_pkey PRIMARY KEY (id);
+
+CREATE INDEX unbonds_address_id_index ON public.unbonds USING btree (address_id);
+
+CREATE INDEX orders_liquidity_pool_id_index ON public.orders USING btree (liquidity_pool_id);
+
+
+--
+-- Name: unbonds, liquidity_pools, address_liquidity_pools, orders; Type: FK CONSTRAINT; Schema: public; Owner: minter
+--
+
+ALTER TABLE ONLY public.unbonds
+    ADD CONSTRAINT unbonds_addresses_id_fk FOREIGN KEY (address_id) REFERENCES public.addresses (id);
+
+ALTER TABLE ONLY public.unbonds
+    ADD CONSTRAINT unbonds_coins_id_fk FOREIGN KEY (coin_id) REFERENCES public.coins (id);
+
+ALTER TABLE ONLY public.unbonds
+    ADD CONSTRAINT unbonds_validators_id_fk FOREIGN KEY (validator_id) REFERENCES public.validators (id);
+
+ALTER TABLE ONLY public.liquidity_pools
+    ADD CONSTRAINT liquidity_pools_coins_id_fk FOREIGN KEY (token_id) REFERENCES public.coins (id);
+
+ALTER TABLE ONLY public.liquidity_pools
+    ADD CONSTRAINT liquidity_pools_first_coins_id_fk FOREIGN KEY (first_coin_id) REFERENCES public.coins (id);
+
+ALTER TABLE ONLY public.liquidity_pools
+    ADD CONSTRAINT liquidity_pools_second_coins_id_fk FOREIGN KEY (second_coin_id) REFERENCES public.coins (id);
+
+ALTER TABLE ONLY public.address_liquidity_pools
+    ADD CONSTRAINT address_liquidity_pools_addresses_id_fk FOREIGN KEY (address_id) REFERENCES public.addresses (id);
+
+ALTER TABLE ONLY public.address_liquidity_pools
+    ADD CONSTRAINT address_liquidity_pools_liquidity_pools_id_fk FOREIGN KEY (liquidity_pool_id) REFERENCES public.liquidity_pools (id);
+
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_addresses_id_fk FOREIGN KEY (address_id) REFERENCES public.addresses (id);
+
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_liquidity_pools_id_fk FOREIGN KEY (liquidity_pool_id) REFERENCES public.liquidity_pools (id);
+
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_coin_sell_id_fk FOREIGN KEY (coin_sell_id) REFERENCES public.coins (id);
+
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_coin_buy_id_fk FOREIGN KEY (coin_buy_id) REFERENCES public.coins (id);
+
+
 --
 -- Name: SCHEMA public; Type: ACL; Schema: -; Owner: minter
 --
@@ -0,0 +1,9 @@
+package database
+
+import "embed"
+
+// Migrations are schema changes required by the uploader on top of db.sql,
+// every migration is idempotent and applied in file name order
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
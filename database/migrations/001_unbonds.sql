@@ -0,0 +1,20 @@
+--
+-- Frozen funds which are not tied to a candidate: move stake and penalty locks
+--
+
+ALTER TABLE public.unbonds
+    ADD COLUMN IF NOT EXISTS type smallint NOT NULL DEFAULT 1;
+
+ALTER TABLE public.unbonds
+    ADD COLUMN IF NOT EXISTS unlock_block_id bigint;
+
+ALTER TABLE public.unbonds
+    ALTER COLUMN validator_id DROP NOT NULL;
+
+UPDATE public.unbonds
+SET unlock_block_id = block_id
+WHERE unlock_block_id IS NULL;
+
+COMMENT ON COLUMN public.unbonds.type IS '1 - unbond, 2 - move stake, 3 - lock';
+COMMENT ON COLUMN public.unbonds.block_id IS 'Block id, at which the funds are unfrozen, same as unlock_block_id';
+COMMENT ON COLUMN public.unbonds.unlock_block_id IS 'Block id, at which the funds are unfrozen';
@@ -0,0 +1,11 @@
+--
+-- Genesis uploads, wipe command checks chain id against them
+--
+
+CREATE TABLE IF NOT EXISTS public.genesis_uploads
+(
+    chain_id       varchar(64)              NOT NULL,
+    initial_height bigint                   NOT NULL,
+    source         varchar                  NOT NULL,
+    started_at     timestamp with time zone NOT NULL DEFAULT now()
+);
@@ -0,0 +1,8 @@
+--
+-- Merge uploads layer genesis onto existing data, wipe command refuses to delete it
+--
+
+ALTER TABLE public.genesis_uploads
+    ADD COLUMN IF NOT EXISTS merge boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN public.genesis_uploads.merge IS 'Genesis has been layered onto existing data';
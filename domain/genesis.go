@@ -57,7 +57,7 @@ type Candidate struct {
 	PublicKey                string         `json:"public_key"`
 	Commission               uint64         `json:"commission"`
 	Stakes                   []GenesisStake `json:"stakes"`
-	Updates                  []interface{}  `json:"updates"`
+	Updates                  []GenesisStake `json:"updates"`
 	Status                   int64          `json:"status"`
 	JailedUntil              int64          `json:"jailed_until"`
 	LastEditCommissionHeight int64          `json:"last_edit_commission_height"`
@@ -146,6 +146,8 @@ type FrozenFund struct {
 	CandidateID  uint64  `json:"candidate_id"`
 	Coin         uint64  `json:"coin"`
 	Value        string  `json:"value"`
+	// MoveToCandidateID is set for stake moving from CandidateID to another candidate
+	MoveToCandidateID *uint64 `json:"move_to_candidate_id"`
 }
 
 type GenesisStake struct {
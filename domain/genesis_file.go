@@ -49,7 +49,7 @@ type GenesisFileCandidate struct {
 	PublicKey                string                `json:"public_key"`
 	Commission               string                `json:"commission"`
 	Stakes                   []GenesisFileWaitlist `json:"stakes"`
-	Updates                  []interface{}         `json:"updates"`
+	Updates                  []GenesisFileWaitlist `json:"updates"`
 	Status                   string                `json:"status"`
 	JailedUntil              string                `json:"jailed_until"`
 	LastEditCommissionHeight string                `json:"last_edit_commission_height"`
@@ -78,12 +78,13 @@ type GenesisFileCoin struct {
 }
 
 type GenesisFileFrozenFund struct {
-	Height       string  `json:"height"`
-	Address      string  `json:"address"`
-	CandidateKey *string `json:"candidate_key"`
-	CandidateID  string  `json:"candidate_id"`
-	Coin         string  `json:"coin"`
-	Value        string  `json:"value"`
+	Height            string  `json:"height"`
+	Address           string  `json:"address"`
+	CandidateKey      *string `json:"candidate_key"`
+	CandidateID       string  `json:"candidate_id"`
+	Coin              string  `json:"coin"`
+	Value             string  `json:"value"`
+	MoveToCandidateID *string `json:"move_to_candidate_id,omitempty"`
 }
 
 type GenesisFilePool struct {
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// GenesisUpload records an upload run, saved before any genesis data
+type GenesisUpload struct {
+	ChainID       string    `json:"chain_id"`
+	InitialHeight uint64    `json:"initial_height" pg:",use_zero"`
+	Source        string    `json:"source"`
+	Merge         bool      `json:"merge"          pg:",use_zero"`
+	StartedAt     time.Time `json:"started_at"`
+}
@@ -1,6 +1,14 @@
 package domain
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultPoolTokenPrefix is the pool token symbol prefix of current networks,
+// older networks used "P-"
+const DefaultPoolTokenPrefix = "LP-"
 
 type LiquidityPool struct {
 	Id               uint64 `json:"id"                 pg:",pk"`
@@ -20,6 +28,23 @@ type AddressLiquidityPool struct {
 	Liquidity       string `json:"liquidity"`
 }
 
-func (lp *LiquidityPool) GetTokenSymbol() string {
-	return fmt.Sprintf("P-%d", lp.Id)
+func (lp *LiquidityPool) GetTokenSymbol(prefix string) string {
+	return PoolTokenSymbol(prefix, lp.Id)
+}
+
+// PoolTokenSymbol returns symbol of the pool token
+func PoolTokenSymbol(prefix string, poolId uint64) string {
+	return fmt.Sprintf("%s%d", prefix, poolId)
+}
+
+// ParsePoolTokenSymbol returns pool id if symbol is a pool token symbol
+func ParsePoolTokenSymbol(prefix, symbol string) (uint64, bool) {
+	if !strings.HasPrefix(symbol, prefix) {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(symbol, prefix), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
 }
@@ -1,9 +1,23 @@
 package domain
 
+type UnbondType byte
+
+const (
+	_ UnbondType = iota
+	// UnbondTypeUnbond stake unbonded from a candidate
+	UnbondTypeUnbond
+	// UnbondTypeMoveStake stake moving to another candidate
+	UnbondTypeMoveStake
+	// UnbondTypeLock funds locked without a candidate, e.g. penalties
+	UnbondTypeLock
+)
+
 type Unbond struct {
-	BlockId     uint   `json:"block_id"`
-	AddressId   uint   `json:"address_id"`
-	CoinId      uint   `json:"coin_id" pg:",use_zero"`
-	ValidatorId uint   `json:"validator_id"`
-	Value       string `json:"value"`
+	BlockId       uint       `json:"block_id"`
+	UnlockBlockId uint       `json:"unlock_block_id"`
+	AddressId     uint       `json:"address_id"`
+	CoinId        uint       `json:"coin_id" pg:",use_zero"`
+	ValidatorId   *uint      `json:"validator_id"`
+	Type          UnbondType `json:"type"`
+	Value         string     `json:"value"`
 }
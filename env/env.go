@@ -1,18 +1,69 @@
 package env
 
+// Config of the uploader. Every field is read from the toml key of the same name,
+// the environment variable in `env` tag (the second name is a legacy alias)
+// and the command line flag in `flag` tag.
 type Config struct {
-	Debug              bool
-	PostgresHost       string
-	PostgresPort       string
-	PostgresDB         string
-	PostgresUser       string
-	PostgresPassword   string
-	PostgresSSLEnabled bool
-	MinterBaseCoin     string
-	NodeGrpc           string
-	AddressChunkSize   uint64
-	CoinsChunkSize     uint64
-	BalanceChunkSize   uint64
-	StakeChunkSize     uint64
-	ValidatorChunkSize uint64
+	Debug              bool              `env:"DEBUG" flag:"debug"`
+	LogLevel           string            `env:"APP_LOG_LEVEL" flag:"log-level"`
+	LogFormat          string            `env:"APP_LOG_FORMAT" flag:"log-format"`
+	LogOutput          string            `env:"APP_LOG_OUTPUT" flag:"log-output"`
+	RunID              string            `env:"APP_RUN_ID" flag:"run-id"`
+	PostgresHost       string            `env:"DB_HOST,POSTGRES_HOST" flag:"db-host"`
+	PostgresPort       string            `env:"DB_PORT,POSTGRES_PORT" flag:"db-port"`
+	PostgresDB         string            `env:"DB_NAME,POSTGRES_NAME" flag:"db-name"`
+	PostgresUser       string            `env:"DB_USER,POSTGRES_USER" flag:"db-user"`
+	PostgresPassword   string            `env:"DB_PASSWORD,POSTGRES_PASSWORD" flag:"db-password" secret:"true"`
+	PostgresSSLEnabled bool              `env:"DB_SSL_ENABLED,POSTGRES_SSL_ENABLED" flag:"db-ssl-enabled"`
+	MinterBaseCoin     string            `env:"MINTER_BASE_COIN" flag:"base-coin"`
+	NodeGrpc           string            `env:"NODE_GRPC" flag:"node-grpc"`
+	GenesisFile        string            `env:"APP_GENESIS_FILE" flag:"file"`
+	AddressChunkSize   uint64            `env:"APP_ADDRESS_CHUNK_SIZE" flag:"address-chunk-size"`
+	CoinsChunkSize     uint64            `env:"APP_COINS_CHUNK_SIZE" flag:"coins-chunk-size"`
+	BalanceChunkSize   uint64            `env:"APP_BALANCES_CHUNK_SIZE" flag:"balances-chunk-size"`
+	StakeChunkSize     uint64            `env:"APP_STAKE_CHUNK_SIZE" flag:"stake-chunk-size"`
+	ValidatorChunkSize uint64            `env:"APP_VALIDATORS_CHUNK_SIZE" flag:"validators-chunk-size"`
+	PoolTokenPrefix    string            `env:"APP_POOL_TOKEN_PREFIX" flag:"pool-token-prefix"`
+	ReportPath         string            `env:"APP_REPORT_PATH" flag:"report"`
+	ErrorPolicy        string            `env:"APP_ERROR_POLICY" flag:"error-policy"`
+	Merge              bool              `env:"APP_MERGE" flag:"merge"`
+	SkipThresholds     map[string]uint64 `env:"APP_SKIP_THRESHOLDS" flag:"skip-thresholds"`
+	MetricsAddr        string            `env:"APP_METRICS_ADDR" flag:"metrics-addr"`
+	MetricsTextfile    string            `env:"APP_METRICS_TEXTFILE" flag:"metrics-textfile"`
+	StatusAddr         string            `env:"APP_STATUS_ADDR" flag:"status-addr"`
+	StatusLinger       uint64            `env:"APP_STATUS_LINGER" flag:"status-linger"`
+	Progress           string            `env:"APP_PROGRESS" flag:"progress"`
+	ProgressInterval   uint64            `env:"APP_PROGRESS_INTERVAL" flag:"progress-interval"`
+}
+
+// Stages of the upload in the order they run, keys of SkipThresholds
+var Stages = []string{
+	"addresses",
+	"coins",
+	"validators",
+	"balances",
+	"stakes",
+	"unbonds",
+	"liquidity_pools",
+	"address_liquidity_pools",
+	"orders",
+}
+
+// Defaults returns config used for fields missing in every source
+func Defaults() Config {
+	return Config{
+		LogFormat:          "text",
+		LogOutput:          "stdout",
+		PostgresPort:       "5432",
+		MinterBaseCoin:     "BIP",
+		AddressChunkSize:   10000,
+		CoinsChunkSize:     1000,
+		BalanceChunkSize:   10000,
+		StakeChunkSize:     10000,
+		ValidatorChunkSize: 300,
+		PoolTokenPrefix:    "LP-",
+		ErrorPolicy:        "lenient",
+		Progress:           "auto",
+		ProgressInterval:   10,
+	}
 }
@@ -0,0 +1,284 @@
+package env
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Problems lists everything wrong with the config
+type Problems []string
+
+func (p Problems) Error() string {
+	return "invalid config:\n  - " + strings.Join(p, "\n  - ")
+}
+
+// Loader builds Config from defaults, then TOML file, then environment, then flags
+type Loader struct {
+	fs    *flag.FlagSet
+	flags map[string]*fieldFlag
+}
+
+// fieldFlag keeps raw value of a config flag until it is applied
+type fieldFlag struct {
+	value  string
+	isBool bool
+}
+
+func (f *fieldFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+func (f *fieldFlag) Set(value string) error {
+	f.value = value
+	return nil
+}
+
+func (f *fieldFlag) IsBoolFlag() bool {
+	return f.isBool
+}
+
+// NewLoader registers a flag for every config field in the flag set
+func NewLoader(fs *flag.FlagSet) *Loader {
+	l := &Loader{fs: fs, flags: make(map[string]*fieldFlag)}
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("flag")
+		if name == "" {
+			continue
+		}
+		f := &fieldFlag{isBool: field.Type.Kind() == reflect.Bool}
+		l.flags[name] = f
+		fs.Var(f, name, fmt.Sprintf("%s (env %s)", field.Name, envNames(field)[0]))
+	}
+	return l
+}
+
+// Load reads config, path is an optional TOML file. Empty environment variables
+// are ignored, NAME_FILE variable of a secret field is read instead of NAME.
+// Returned Problems list every invalid value found in any source.
+func (l *Loader) Load(path string) (Config, error) {
+	cfg := Defaults()
+	var problems Problems
+
+	if path != "" {
+		meta, err := toml.DecodeFile(path, &cfg)
+		if err != nil {
+			return cfg, Problems{err.Error()}
+		}
+		for _, key := range meta.Undecoded() {
+			problems = append(problems, fmt.Sprintf("%s: unknown key %q", path, key.String()))
+		}
+	}
+
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		problems = append(problems, fmt.Sprintf(".env: %s", err))
+	}
+
+	v := reflect.ValueOf(&cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		for _, name := range envNames(field) {
+			value := os.Getenv(name)
+			if file := os.Getenv(name + "_FILE"); file != "" && field.Tag.Get("secret") == "true" {
+				name += "_FILE"
+				secret, err := ioutil.ReadFile(file)
+				if err != nil {
+					problems = append(problems, fmt.Sprintf("%s: %s", name, err))
+					break
+				}
+				value = strings.TrimRight(string(secret), "\r\n")
+			}
+			if value == "" {
+				continue
+			}
+			if err := set(v.Field(i), value); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %s", name, err))
+			}
+			break
+		}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("flag")
+		f, ok := l.flags[name]
+		if !ok || !l.isSet(name) {
+			continue
+		}
+		if err := set(v.Field(i), f.value); err != nil {
+			problems = append(problems, fmt.Sprintf("-%s: %s", name, err))
+		}
+	}
+
+	problems = append(problems, cfg.Validate()...)
+	if len(problems) > 0 {
+		return cfg, problems
+	}
+	return cfg, nil
+}
+
+func (l *Loader) isSet(name string) bool {
+	set := false
+	l.fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func envNames(field reflect.StructField) []string {
+	return strings.Split(field.Tag.Get("env"), ",")
+}
+
+// set parses value into the config field
+func set(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		field.SetBool(b)
+	case reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		field.SetUint(n)
+	case reflect.Map:
+		thresholds, err := ParseThresholds(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(thresholds))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// ParseThresholds parses "balances=100,orders=0" into stage thresholds
+func ParseThresholds(value string) (map[string]uint64, error) {
+	thresholds := make(map[string]uint64)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid skip threshold %q", item)
+		}
+		threshold, err := strconv.ParseUint(strings.TrimSpace(parts[1]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid skip threshold %q", item)
+		}
+		thresholds[strings.TrimSpace(parts[0])] = threshold
+	}
+	return thresholds, nil
+}
+
+// ValidateDB returns problems of settings required to connect to Postgres
+func (c Config) ValidateDB() Problems {
+	var problems Problems
+	if c.PostgresHost == "" {
+		problems = append(problems, "PostgresHost: required")
+	}
+	if port, err := strconv.ParseUint(c.PostgresPort, 10, 16); err != nil || port == 0 {
+		problems = append(problems, fmt.Sprintf("PostgresPort: %q is not a valid port", c.PostgresPort))
+	}
+	if c.PostgresDB == "" {
+		problems = append(problems, "PostgresDB: required")
+	}
+	if c.PostgresUser == "" {
+		problems = append(problems, "PostgresUser: required")
+	}
+	return problems
+}
+
+// ValidateSource returns problems of settings required to load genesis
+func (c Config) ValidateSource() Problems {
+	if c.NodeGrpc == "" && c.GenesisFile == "" {
+		return Problems{"NodeGrpc or GenesisFile: one of them is required"}
+	}
+	return nil
+}
+
+// Validate returns every invalid field value, settings required only by
+// some commands are checked by ValidateDB and ValidateSource
+func (c Config) Validate() Problems {
+	var problems Problems
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	oneOf := func(field, value string, allowed ...string) {
+		for _, a := range allowed {
+			if value == a {
+				return
+			}
+		}
+		add("%s: %q must be one of %s", field, value, strings.Join(allowed, ", "))
+	}
+
+	oneOf("LogLevel", c.LogLevel, "", "trace", "debug", "info", "warn", "warning", "error", "fatal", "panic")
+	oneOf("LogFormat", c.LogFormat, "text", "json")
+	if c.LogOutput == "" {
+		add("LogOutput: must be stdout, stderr or a file path")
+	}
+
+	if c.MinterBaseCoin == "" {
+		add("MinterBaseCoin: required")
+	}
+
+	chunks := []struct {
+		name  string
+		value uint64
+	}{
+		{"AddressChunkSize", c.AddressChunkSize},
+		{"CoinsChunkSize", c.CoinsChunkSize},
+		{"BalanceChunkSize", c.BalanceChunkSize},
+		{"StakeChunkSize", c.StakeChunkSize},
+		{"ValidatorChunkSize", c.ValidatorChunkSize},
+	}
+	for _, chunk := range chunks {
+		if chunk.value == 0 {
+			add("%s: must be greater than 0", chunk.name)
+		}
+	}
+
+	if c.PoolTokenPrefix == "" {
+		add("PoolTokenPrefix: required")
+	}
+	oneOf("ErrorPolicy", c.ErrorPolicy, "strict", "lenient")
+	oneOf("Progress", c.Progress, "auto", "tty", "log", "off")
+	if c.ProgressInterval == 0 {
+		add("ProgressInterval: must be greater than 0")
+	}
+
+	stages := make([]string, 0, len(c.SkipThresholds))
+	for stage := range c.SkipThresholds {
+		stages = append(stages, stage)
+	}
+	sort.Strings(stages)
+	for _, stage := range stages {
+		oneOf("SkipThresholds", stage, Stages...)
+	}
+
+	return problems
+}
@@ -32,12 +32,45 @@ func (r *Address) SaveAll(addresses []string) error {
 	return err
 }
 
+// UpsertAll saves new addresses and caches ids of all of them, existing addresses keep their ids
+func (r *Address) UpsertAll(addresses []string) error {
+	list := make([]*domain.Address, len(addresses))
+	for i, a := range addresses {
+		list[i] = &domain.Address{Address: a}
+	}
+	_, err := r.DB.Model(&list).OnConflict("(address) DO NOTHING").Insert()
+	if err != nil {
+		return err
+	}
+
+	var saved []*domain.Address
+	err = r.DB.Model(&saved).Where("address IN (?)", pg.In(addresses)).Select()
+	if err == nil {
+		r.addToCache(saved)
+	}
+	return err
+}
+
+// Assign caches ids the addresses get when inserted in the given order into an empty table,
+// used when there is no DB
+func (r *Address) Assign(addresses []string) []*domain.Address {
+	list := make([]*domain.Address, len(addresses))
+	for i, a := range addresses {
+		list[i] = &domain.Address{ID: uint64(i + 1), Address: a}
+	}
+	r.addToCache(list)
+	return list
+}
+
 func (r *Address) FindId(address string) (uint64, error) {
 	//First look in the cache
 	id, ok := r.cache.Load(address)
 	if ok {
 		return id.(uint64), nil
 	}
+	if r.DB == nil {
+		return 0, pg.ErrNoRows
+	}
 
 	adr := new(domain.Address)
 	err := r.DB.Model(adr).Column("id").Where("address = ?", address).Select(adr)
@@ -47,6 +80,12 @@ func (r *Address) FindId(address string) (uint64, error) {
 	return adr.ID, nil
 }
 
+func (r *Address) GetAll() ([]*domain.Address, error) {
+	var list []*domain.Address
+	err := r.DB.Model(&list).Select()
+	return list, err
+}
+
 func (r *Address) GetAddressesCount() (int, error) {
 	return r.DB.Model((*domain.Address)(nil)).Count()
 }
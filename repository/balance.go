@@ -19,6 +19,40 @@ func (r *Balance) SaveAll(balances []*domain.Balance) error {
 	return err
 }
 
+// UpsertAll saves balances replacing values of existing address and coin pairs
+func (r *Balance) UpsertAll(balances []*domain.Balance) error {
+	_, err := onConflictUpdate(r.db.Model(&balances), "address_id, coin_id", "value").Insert()
+	return err
+}
+
+func (r *Balance) GetAll() ([]*domain.Balance, error) {
+	var list []*domain.Balance
+	err := r.db.Model(&list).Select()
+	return list, err
+}
+
 func (r *Balance) GetBalancesCount() (int, error) {
 	return r.db.Model((*domain.Balance)(nil)).Count()
 }
+
+// GetSumsByCoin returns sum of balances for every coin
+func (r *Balance) GetSumsByCoin() (map[uint64]string, error) {
+	var rows []struct {
+		CoinID uint64
+		Sum    string
+	}
+	err := r.db.Model((*domain.Balance)(nil)).
+		Column("coin_id").
+		ColumnExpr("sum(value)::text AS sum").
+		Group("coin_id").
+		Select(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	sums := make(map[uint64]string, len(rows))
+	for _, row := range rows {
+		sums[row.CoinID] = row.Sum
+	}
+	return sums, nil
+}
@@ -30,6 +30,19 @@ func (r *Coin) SaveAll(coins []*domain.Coin) error {
 	return err
 }
 
+// UpsertAll saves coins replacing existing coins with the same id, the uploader gives
+// genesis coins ids of stored coins with the same symbols beforehand
+func (r *Coin) UpsertAll(coins []*domain.Coin) error {
+	q := onConflictUpdate(r.db.Model(&coins), "id",
+		"type", "name", "symbol", "volume", "crr", "reserve", "max_supply", "version", "owner_address_id")
+	_, err := q.Insert()
+	for _, coin := range coins {
+		r.cache.Store(coin.Symbol, coin.ID)
+		r.invCache.Store(coin.ID, coin.Symbol)
+	}
+	return err
+}
+
 // Find coin id by symbol
 func (r *Coin) FindIdBySymbol(symbol string) (uint64, error) {
 	//First look in the cache
@@ -63,10 +76,20 @@ func (r *Coin) FindBySymbol(symbol string) (*domain.Coin, error) {
 	return coin, nil
 }
 
+func (r *Coin) GetAll() ([]*domain.Coin, error) {
+	var list []*domain.Coin
+	err := r.db.Model(&list).Order("id").Select()
+	return list, err
+}
+
 func (r *Coin) GetCoinsCount() (int, error) {
 	return r.db.Model((*domain.Coin)(nil)).Where("symbol != ?", os.Getenv("MINTER_BASE_COIN")).Count()
 }
 
+func (r *Coin) GetAllCoinsCount() (int, error) {
+	return r.db.Model((*domain.Coin)(nil)).Count()
+}
+
 func (r *Coin) ChangeSequence(i int) error {
 	_, err := r.db.Model().Exec(`
 		alter sequence coins_id_seq START WITH ?;
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"github.com/MinterTeam/explorer-genesis-uploader/domain"
+	"github.com/go-pg/pg/v10"
+)
+
+type GenesisUpload struct {
+	db *pg.DB
+}
+
+func NewGenesisUploadRepository(db *pg.DB) *GenesisUpload {
+	return &GenesisUpload{
+		db: db,
+	}
+}
+
+func (r *GenesisUpload) Save(upload *domain.GenesisUpload) error {
+	_, err := r.db.Model(upload).Insert()
+	return err
+}
+
+// GetChainIDs returns distinct chain ids of recorded uploads
+func (r *GenesisUpload) GetChainIDs() ([]string, error) {
+	var ids []string
+	err := r.db.Model((*domain.GenesisUpload)(nil)).
+		ColumnExpr("DISTINCT chain_id").
+		Select(&ids)
+	return ids, err
+}
+
+// HasMerge reports whether genesis has been layered onto existing data by any recorded upload
+func (r *GenesisUpload) HasMerge() (bool, error) {
+	return r.db.Model((*domain.GenesisUpload)(nil)).Where("merge").Exists()
+}
+
+// GetLast returns the most recent recorded upload
+func (r *GenesisUpload) GetLast() (*domain.GenesisUpload, error) {
+	upload := new(domain.GenesisUpload)
+	err := r.db.Model(upload).Order("started_at DESC").Limit(1).Select()
+	return upload, err
+}
@@ -15,6 +15,51 @@ func (r *LiquidityPool) SaveAllOrders(orders []domain.Order) error {
 	return err
 }
 
+func (r *LiquidityPool) SaveAllAddressLiquidityPools(list []*domain.AddressLiquidityPool) error {
+	_, err := r.db.Model(&list).Insert()
+	return err
+}
+
+// UpsertAll saves pools replacing existing pools with the same id
+func (r *LiquidityPool) UpsertAll(list []*domain.LiquidityPool) error {
+	q := onConflictUpdate(r.db.Model(&list), "id",
+		"token_id", "first_coin_id", "second_coin_id", "first_coin_volume", "second_coin_volume",
+		"liquidity", "liquidity_bip", "updated_at_block_id")
+	_, err := q.Insert()
+	return err
+}
+
+// UpsertAllOrders saves orders replacing existing orders with the same id
+func (r *LiquidityPool) UpsertAllOrders(orders []domain.Order) error {
+	q := onConflictUpdate(r.db.Model(&orders), "id",
+		"address_id", "liquidity_pool_id", "price", "coin_sell_id", "coin_sell_volume",
+		"coin_buy_id", "coin_buy_volume", "created_at_block", "status")
+	_, err := q.Insert()
+	return err
+}
+
+// UpsertAllAddressLiquidityPools saves liquidity of addresses replacing existing values
+func (r *LiquidityPool) UpsertAllAddressLiquidityPools(list []*domain.AddressLiquidityPool) error {
+	_, err := onConflictUpdate(r.db.Model(&list), "liquidity_pool_id, address_id", "liquidity").Insert()
+	return err
+}
+
+func (r *LiquidityPool) GetAll() ([]*domain.LiquidityPool, error) {
+	var list []*domain.LiquidityPool
+	err := r.db.Model(&list).Select()
+	return list, err
+}
+
+func (r *LiquidityPool) GetAllOrders() ([]domain.Order, error) {
+	var list []domain.Order
+	err := r.db.Model(&list).Select()
+	return list, err
+}
+
+func (r *LiquidityPool) GetAddressLiquidityPoolsCount() (int, error) {
+	return r.db.Model((*domain.AddressLiquidityPool)(nil)).Count()
+}
+
 type LiquidityPool struct {
 	db *pg.DB
 }
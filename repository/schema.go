@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"github.com/go-pg/pg/v10"
+)
+
+type Schema struct {
+	db *pg.DB
+}
+
+func NewSchemaRepository(db *pg.DB) *Schema {
+	return &Schema{
+		db: db,
+	}
+}
+
+// Exec runs sql script in a transaction
+func (r *Schema) Exec(script string) error {
+	return r.db.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+		_, err := tx.Exec(script)
+		return err
+	})
+}
+
+// Probe selects no rows of every model, so the first missing table or column of the models
+// is returned as an error. Models are pointers to slices.
+func (r *Schema) Probe(models ...interface{}) error {
+	for _, model := range models {
+		if err := r.db.Model(model).Limit(0).Select(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Wipe deletes all rows of the tables in the given order and restarts
+// sequences owned by them. Returns count of deleted rows per table.
+func (r *Schema) Wipe(tables []string) (map[string]int, error) {
+	deleted := make(map[string]int, len(tables))
+	err := r.db.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+		for _, table := range tables {
+			res, err := tx.Exec(`DELETE FROM ?`, pg.Ident(table))
+			if err != nil {
+				return err
+			}
+			deleted[table] = res.RowsAffected()
+		}
+		for _, table := range tables {
+			_, err := tx.Exec(`
+				SELECT setval(d.objid::regclass, 1, false)
+				FROM pg_depend d
+					JOIN pg_class s ON s.oid = d.objid
+				WHERE d.refobjid = ?::regclass AND s.relkind = 'S' AND d.deptype IN ('a', 'i')`, table)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// onConflictUpdate turns insert into upsert, on conflict with the key
+// the given columns of the existing row are replaced
+func onConflictUpdate(q *orm.Query, key string, columns ...string) *orm.Query {
+	q = q.OnConflict("(" + key + ") DO UPDATE")
+	for _, column := range columns {
+		q = q.Set(column + " = EXCLUDED." + column)
+	}
+	return q
+}
@@ -23,6 +23,14 @@ func (r *Validator) SaveAll(validators []*domain.Validator) error {
 	return err
 }
 
+// UpsertAll saves validators replacing genesis data of existing ones, metadata is kept
+func (r *Validator) UpsertAll(validators []*domain.Validator) error {
+	q := onConflictUpdate(r.db.Model(&validators), "id",
+		"reward_address_id", "owner_address_id", "status", "public_key", "commission", "total_stake")
+	_, err := q.Insert()
+	return err
+}
+
 // GetById Find validator with public key.
 // Return ValidatorID
 func (r *Validator) GetById(id uint) (uint, error) {
@@ -42,6 +50,9 @@ func (r *Validator) FindIdByPk(pk string) (uint, error) {
 	if ok {
 		return id.(uint), nil
 	}
+	if r.db == nil {
+		return 0, pg.ErrNoRows
+	}
 
 	vpk := new(domain.ValidatorPublicKeys)
 	err := r.db.Model(vpk).Where("key = ?", pk).Select()
@@ -53,20 +64,149 @@ func (r *Validator) FindIdByPk(pk string) (uint, error) {
 	return vpk.ValidatorId, nil
 }
 
+// CachePks caches public keys of validators, used when there is no DB
+func (r *Validator) CachePks(validators []*domain.Validator) {
+	for _, v := range validators {
+		r.cache.Store(v.PublicKey, v.ID)
+	}
+}
+
 func (r *Validator) SaveAllStakes(stakes []*domain.Stake) error {
 	_, err := r.db.Model(&stakes).Insert()
 	return err
 }
 
+// UpsertAllStakes saves stakes replacing values of existing ones
+func (r *Validator) UpsertAllStakes(stakes []*domain.Stake) error {
+	q := onConflictUpdate(r.db.Model(&stakes), "validator_id, owner_address_id, coin_id", "value", "bip_value")
+	_, err := q.Insert()
+	return err
+}
+
+// UpsertAllUnbonds saves unbonds which are not stored yet, unbonds have no key
+// so a stored row must match in every column
+func (r *Validator) UpsertAllUnbonds(list []*domain.Unbond) error {
+	addresses := make([]uint, len(list))
+	for i, u := range list {
+		addresses[i] = u.AddressId
+	}
+	var existing []*domain.Unbond
+	err := r.db.Model(&existing).Where("address_id IN (?)", pg.In(addresses)).Select()
+	if err != nil {
+		return err
+	}
+
+	stored := make(map[unbondKey]struct{}, len(existing))
+	for _, u := range existing {
+		stored[newUnbondKey(u)] = struct{}{}
+	}
+	var missing []*domain.Unbond
+	for _, u := range list {
+		if _, ok := stored[newUnbondKey(u)]; !ok {
+			missing = append(missing, u)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	_, err = r.db.Model(&missing).Insert()
+	return err
+}
+
+// unbondKey is a comparable form of unbond
+type unbondKey struct {
+	blockId       uint
+	unlockBlockId uint
+	addressId     uint
+	coinId        uint
+	validatorId   uint
+	hasValidator  bool
+	kind          domain.UnbondType
+	value         string
+}
+
+func newUnbondKey(u *domain.Unbond) unbondKey {
+	key := unbondKey{
+		blockId:       u.BlockId,
+		unlockBlockId: u.UnlockBlockId,
+		addressId:     u.AddressId,
+		coinId:        u.CoinId,
+		kind:          u.Type,
+		value:         u.Value,
+	}
+	if u.ValidatorId != nil {
+		key.validatorId, key.hasValidator = *u.ValidatorId, true
+	}
+	return key
+}
+
 func (r *Validator) SaveAllUnbonds(list []*domain.Unbond) error {
 	_, err := r.db.Model(&list).Insert()
 	return err
 }
 
+func (r *Validator) GetAll() ([]*domain.Validator, error) {
+	var list []*domain.Validator
+	err := r.db.Model(&list).Order("id").Select()
+	return list, err
+}
+
+func (r *Validator) GetAllPk() ([]*domain.ValidatorPublicKeys, error) {
+	var list []*domain.ValidatorPublicKeys
+	err := r.db.Model(&list).Select()
+	return list, err
+}
+
+func (r *Validator) GetAllStakes() ([]*domain.Stake, error) {
+	var list []*domain.Stake
+	err := r.db.Model(&list).Select()
+	return list, err
+}
+
+func (r *Validator) GetAllUnbonds() ([]*domain.Unbond, error) {
+	var list []*domain.Unbond
+	err := r.db.Model(&list).Select()
+	return list, err
+}
+
 func (r *Validator) GetValidatorsCount() (int, error) {
 	return r.db.Model((*domain.Validator)(nil)).Count()
 }
 
+func (r *Validator) GetStakesCount() (int, error) {
+	return r.db.Model((*domain.Stake)(nil)).Count()
+}
+
+func (r *Validator) GetUnbondsCount() (int, error) {
+	return r.db.Model((*domain.Unbond)(nil)).Count()
+}
+
+// GetStakeSums returns sum of stakes for every validator and coin
+func (r *Validator) GetStakeSums() (map[uint]map[uint64]string, error) {
+	var rows []struct {
+		ValidatorID uint
+		CoinID      uint64
+		Sum         string
+	}
+	err := r.db.Model((*domain.Stake)(nil)).
+		Column("validator_id", "coin_id").
+		ColumnExpr("sum(value)::text AS sum").
+		Group("validator_id", "coin_id").
+		Select(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	sums := make(map[uint]map[uint64]string)
+	for _, row := range rows {
+		if sums[row.ValidatorID] == nil {
+			sums[row.ValidatorID] = make(map[uint64]string)
+		}
+		sums[row.ValidatorID][row.CoinID] = row.Sum
+	}
+	return sums, nil
+}
+
 func (r *Validator) AddPk(key string, validatorId uint) (*domain.ValidatorPublicKeys, error) {
 	vpk := &domain.ValidatorPublicKeys{
 		Key:         key,
@@ -81,6 +221,38 @@ func (r *Validator) SaveAllPk(vpk []*domain.ValidatorPublicKeys) error {
 	return err
 }
 
+// UpsertAllPk saves public keys which are not stored yet
+func (r *Validator) UpsertAllPk(vpk []*domain.ValidatorPublicKeys) error {
+	keys := make([]string, len(vpk))
+	for i, pk := range vpk {
+		keys[i] = pk.Key
+	}
+	var existing []string
+	err := r.db.Model((*domain.ValidatorPublicKeys)(nil)).
+		Column("key").
+		Where("key IN (?)", pg.In(keys)).
+		Select(&existing)
+	if err != nil {
+		return err
+	}
+
+	stored := make(map[string]struct{}, len(existing))
+	for _, key := range existing {
+		stored[key] = struct{}{}
+	}
+	var missing []*domain.ValidatorPublicKeys
+	for _, pk := range vpk {
+		if _, ok := stored[pk.Key]; !ok {
+			missing = append(missing, pk)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	_, err = r.db.Model(&missing).Insert()
+	return err
+}
+
 func (r *Validator) Add(v *domain.Validator) (*domain.Validator, error) {
 	_, err := r.db.Model(v).Insert()
 	return v, err
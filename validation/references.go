@@ -0,0 +1,185 @@
+package validation
+
+import (
+	"fmt"
+	"github.com/MinterTeam/explorer-genesis-uploader/domain"
+	"regexp"
+	"strconv"
+)
+
+var (
+	addressRe   = regexp.MustCompile(`^Mx[0-9a-fA-F]{40}$`)
+	publicKeyRe = regexp.MustCompile(`^Mp[0-9a-fA-F]{64}$`)
+)
+
+type references struct {
+	*checker
+	coins      map[uint64]struct{}
+	candidates map[uint64]string
+	keys       map[string]uint64
+}
+
+func (r *references) address(path, address string) {
+	if !addressRe.MatchString(address) {
+		r.violate(RuleFormat, path, fmt.Sprintf("invalid address %q", address))
+	}
+}
+
+func (r *references) coin(path string, id uint64) {
+	if _, ok := r.coins[id]; !ok && id != baseCoinId {
+		r.violate(RuleReference, path, fmt.Sprintf("unknown coin %d", id))
+	}
+}
+
+func (r *references) candidate(path string, id uint64) {
+	if _, ok := r.candidates[id]; !ok {
+		r.violate(RuleReference, path, fmt.Sprintf("unknown candidate %d", id))
+	}
+}
+
+// CheckReferences checks that every coin, candidate and address referenced
+// in genesis exists and that identifiers are unique
+func CheckReferences(genesis *domain.Genesis) []Violation {
+	r := &references{
+		checker:    new(checker),
+		coins:      make(map[uint64]struct{}),
+		candidates: make(map[uint64]string),
+		keys:       make(map[string]uint64),
+	}
+
+	symbols := make(map[string]int)
+	for i, c := range genesis.AppState.Coins {
+		path := fmt.Sprintf("app_state.coins[%d]", i)
+		if _, ok := r.coins[c.ID]; ok {
+			r.violate(RuleDuplicate, path+".id", fmt.Sprintf("duplicate coin id %d", c.ID))
+		}
+		r.coins[c.ID] = struct{}{}
+		if j, ok := symbols[c.Symbol]; ok {
+			r.violate(RuleDuplicate, path+".symbol", fmt.Sprintf("symbol %s is already used by app_state.coins[%d]", c.Symbol, j))
+		} else {
+			symbols[c.Symbol] = i
+		}
+		if c.OwnerAddress != nil && *c.OwnerAddress != "" {
+			r.address(path+".owner_address", *c.OwnerAddress)
+		}
+	}
+
+	for i, c := range genesis.AppState.Candidates {
+		path := fmt.Sprintf("app_state.candidates[%d]", i)
+		if _, ok := r.candidates[c.ID]; ok {
+			r.violate(RuleDuplicate, path+".id", fmt.Sprintf("duplicate candidate id %d", c.ID))
+		}
+		r.candidates[c.ID] = c.PublicKey
+		if !publicKeyRe.MatchString(c.PublicKey) {
+			r.violate(RuleFormat, path+".public_key", fmt.Sprintf("invalid public key %q", c.PublicKey))
+		}
+		if _, ok := r.keys[c.PublicKey]; ok {
+			r.violate(RuleDuplicate, path+".public_key", fmt.Sprintf("duplicate public key %s", c.PublicKey))
+		}
+		r.keys[c.PublicKey] = c.ID
+		r.address(path+".owner_address", c.OwnerAddress)
+		r.address(path+".reward_address", c.RewardAddress)
+		if c.ControlAddress != "" {
+			r.address(path+".control_address", c.ControlAddress)
+		}
+		for j, s := range c.Stakes {
+			stakePath := fmt.Sprintf("%s.stakes[%d]", path, j)
+			r.address(stakePath+".owner", s.Owner)
+			r.coin(stakePath+".coin", s.Coin)
+		}
+		for j, s := range c.Updates {
+			updatePath := fmt.Sprintf("%s.updates[%d]", path, j)
+			r.address(updatePath+".owner", s.Owner)
+			r.coin(updatePath+".coin", s.Coin)
+		}
+	}
+
+	addresses := make(map[string]int)
+	for i, a := range genesis.AppState.Accounts {
+		path := fmt.Sprintf("app_state.accounts[%d]", i)
+		r.address(path+".address", a.Address)
+		if j, ok := addresses[a.Address]; ok {
+			r.violate(RuleDuplicate, path+".address", fmt.Sprintf("address %s is already used by app_state.accounts[%d]", a.Address, j))
+		} else {
+			addresses[a.Address] = i
+		}
+		coins := make(map[uint64]struct{})
+		for j, b := range a.Balance {
+			balancePath := fmt.Sprintf("%s.balance[%d].coin", path, j)
+			r.coin(balancePath, b.Coin)
+			if _, ok := coins[b.Coin]; ok {
+				r.violate(RuleDuplicate, balancePath, fmt.Sprintf("duplicate balance of coin %d", b.Coin))
+			}
+			coins[b.Coin] = struct{}{}
+		}
+		if a.MultisigData != nil {
+			for j, address := range a.MultisigData.Addresses {
+				r.address(fmt.Sprintf("%s.multisig_data.addresses[%d]", path, j), address)
+			}
+		}
+	}
+
+	for i, f := range genesis.AppState.FrozenFunds {
+		path := fmt.Sprintf("app_state.frozen_funds[%d]", i)
+		r.address(path+".address", f.Address)
+		r.coin(path+".coin", f.Coin)
+		if f.CandidateID != 0 {
+			r.candidate(path+".candidate_id", f.CandidateID)
+		}
+		if f.MoveToCandidateID != nil {
+			r.candidate(path+".move_to_candidate_id", *f.MoveToCandidateID)
+		}
+		if f.CandidateKey != nil {
+			id, ok := r.keys[*f.CandidateKey]
+			if !ok {
+				r.violate(RuleReference, path+".candidate_key", fmt.Sprintf("unknown candidate %s", *f.CandidateKey))
+			} else if f.CandidateID != 0 && id != f.CandidateID {
+				r.violate(RuleReference, path+".candidate_key", fmt.Sprintf("key belongs to candidate %d, not %d", id, f.CandidateID))
+			}
+		}
+	}
+
+	for i, w := range genesis.AppState.Waitlist {
+		path := fmt.Sprintf("app_state.waitlist[%d]", i)
+		r.address(path+".owner", w.Owner)
+		r.coin(path+".coin", w.Coin)
+		if w.CandidateID == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(w.CandidateID, 10, 64)
+		if err != nil {
+			r.violate(RuleFormat, path+".candidate_id", fmt.Sprintf("invalid candidate id %q", w.CandidateID))
+		} else {
+			r.candidate(path+".candidate_id", id)
+		}
+	}
+
+	pools := make(map[uint64]struct{})
+	orders := make(map[uint64]string)
+	for i, p := range genesis.AppState.Pools {
+		path := fmt.Sprintf("app_state.pools[%d]", i)
+		if _, ok := pools[p.ID]; ok {
+			r.violate(RuleDuplicate, path+".id", fmt.Sprintf("duplicate pool id %d", p.ID))
+		}
+		pools[p.ID] = struct{}{}
+		r.coin(path+".coin0", p.Coin0)
+		r.coin(path+".coin1", p.Coin1)
+		if p.Coin0 == p.Coin1 {
+			r.violate(RuleReference, path+".coin1", fmt.Sprintf("pool %d pairs coin %d with itself", p.ID, p.Coin0))
+		}
+		for j, o := range p.Orders {
+			orderPath := fmt.Sprintf("%s.orders[%d]", path, j)
+			r.address(orderPath+".owner", o.Owner)
+			if other, ok := orders[o.Id]; ok {
+				r.violate(RuleDuplicate, orderPath+".id", fmt.Sprintf("order id %d is already used by %s", o.Id, other))
+			} else {
+				orders[o.Id] = orderPath
+			}
+			if genesis.AppState.NextOrderID != 0 && o.Id >= genesis.AppState.NextOrderID {
+				r.violate(RuleReference, orderPath+".id", fmt.Sprintf("order id %d is not less than next_order_id %d", o.Id, genesis.AppState.NextOrderID))
+			}
+		}
+	}
+
+	return r.violations
+}
@@ -0,0 +1,158 @@
+package validation
+
+import (
+	"github.com/MinterTeam/explorer-genesis-uploader/domain"
+	"reflect"
+	"testing"
+)
+
+const testPublicKey = "Mpabababababababababababababababababababababababababababababababab"
+
+// referencedGenesis references every coin, candidate and address it lists
+func referencedGenesis() *domain.Genesis {
+	key := testPublicKey
+	genesis := balancedGenesis()
+	genesis.AppState.NextOrderID = 3
+	genesis.AppState.Candidates[0].PublicKey = testPublicKey
+	genesis.AppState.Candidates[0].OwnerAddress = testAddress
+	genesis.AppState.Candidates[0].RewardAddress = testAddress
+	genesis.AppState.Waitlist[0].CandidateID = "1"
+	genesis.AppState.FrozenFunds[0].CandidateID = 1
+	genesis.AppState.FrozenFunds[0].CandidateKey = &key
+	genesis.AppState.Pools[0].Orders[0].Owner = testAddress
+	genesis.AppState.Pools[0].Orders[1].Owner = testAddress
+	return genesis
+}
+
+func TestCheckReferences(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*domain.Genesis)
+		rules  []string
+		paths  []string
+	}{
+		{"consistent", func(*domain.Genesis) {}, nil, nil},
+		{
+			"duplicate coin id",
+			func(g *domain.Genesis) { g.AppState.Coins[1].ID = 1 },
+			[]string{RuleDuplicate, RuleReference}, []string{"app_state.coins[1].id", "app_state.accounts[0].balance[2].coin"},
+		},
+		{
+			"duplicate coin symbol",
+			func(g *domain.Genesis) { g.AppState.Coins[1].Symbol = "TEST" },
+			[]string{RuleDuplicate}, []string{"app_state.coins[1].symbol"},
+		},
+		{
+			"invalid public key",
+			func(g *domain.Genesis) { g.AppState.Candidates[0].PublicKey = "Mpab" },
+			[]string{RuleFormat, RuleReference}, []string{"app_state.candidates[0].public_key", "app_state.frozen_funds[0].candidate_key"},
+		},
+		{
+			"invalid owner address",
+			func(g *domain.Genesis) { g.AppState.Candidates[0].OwnerAddress = "Mx01" },
+			[]string{RuleFormat}, []string{"app_state.candidates[0].owner_address"},
+		},
+		{
+			"unknown stake coin",
+			func(g *domain.Genesis) { g.AppState.Candidates[0].Stakes[0].Coin = 9 },
+			[]string{RuleReference}, []string{"app_state.candidates[0].stakes[0].coin"},
+		},
+		{
+			"unknown stake update coin",
+			func(g *domain.Genesis) { g.AppState.Candidates[0].Updates[0].Coin = 9 },
+			[]string{RuleReference}, []string{"app_state.candidates[0].updates[0].coin"},
+		},
+		{
+			"invalid stake update owner",
+			func(g *domain.Genesis) { g.AppState.Candidates[0].Updates[0].Owner = "" },
+			[]string{RuleFormat}, []string{"app_state.candidates[0].updates[0].owner"},
+		},
+		{
+			"duplicate account",
+			func(g *domain.Genesis) {
+				g.AppState.Accounts = append(g.AppState.Accounts, domain.Account{Address: testAddress})
+			},
+			[]string{RuleDuplicate}, []string{"app_state.accounts[1].address"},
+		},
+		{
+			"duplicate balance coin",
+			func(g *domain.Genesis) { g.AppState.Accounts[0].Balance[2].Coin = 1 },
+			[]string{RuleDuplicate}, []string{"app_state.accounts[0].balance[2].coin"},
+		},
+		{
+			"unknown frozen fund candidate",
+			func(g *domain.Genesis) {
+				g.AppState.FrozenFunds[0].CandidateID = 2
+				g.AppState.FrozenFunds[0].CandidateKey = nil
+			},
+			[]string{RuleReference}, []string{"app_state.frozen_funds[0].candidate_id"},
+		},
+		{
+			"frozen fund key of another candidate",
+			func(g *domain.Genesis) {
+				g.AppState.Candidates = append(g.AppState.Candidates, domain.Candidate{
+					ID:            2,
+					PublicKey:     "Mpcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcd",
+					OwnerAddress:  testAddress,
+					RewardAddress: testAddress,
+				})
+				g.AppState.FrozenFunds[0].CandidateID = 2
+			},
+			[]string{RuleReference}, []string{"app_state.frozen_funds[0].candidate_key"},
+		},
+		{
+			"unknown move to candidate",
+			func(g *domain.Genesis) {
+				id := uint64(2)
+				g.AppState.FrozenFunds[0].MoveToCandidateID = &id
+			},
+			[]string{RuleReference}, []string{"app_state.frozen_funds[0].move_to_candidate_id"},
+		},
+		{
+			"invalid waitlist candidate",
+			func(g *domain.Genesis) { g.AppState.Waitlist[0].CandidateID = "one" },
+			[]string{RuleFormat}, []string{"app_state.waitlist[0].candidate_id"},
+		},
+		{
+			"unknown waitlist candidate",
+			func(g *domain.Genesis) { g.AppState.Waitlist[0].CandidateID = "2" },
+			[]string{RuleReference}, []string{"app_state.waitlist[0].candidate_id"},
+		},
+		{
+			"pool of a coin with itself",
+			func(g *domain.Genesis) { g.AppState.Pools[0].Coin0 = 1 },
+			[]string{RuleReference}, []string{"app_state.pools[0].coin1"},
+		},
+		{
+			"duplicate pool id",
+			func(g *domain.Genesis) {
+				g.AppState.Pools = append(g.AppState.Pools, domain.Pool{ID: 1, Coin0: 0, Coin1: 2})
+			},
+			[]string{RuleDuplicate}, []string{"app_state.pools[1].id"},
+		},
+		{
+			"duplicate order id",
+			func(g *domain.Genesis) { g.AppState.Pools[0].Orders[1].Id = 1 },
+			[]string{RuleDuplicate}, []string{"app_state.pools[0].orders[1].id"},
+		},
+		{
+			"order id reaches next order id",
+			func(g *domain.Genesis) { g.AppState.NextOrderID = 2 },
+			[]string{RuleReference}, []string{"app_state.pools[0].orders[1].id"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			genesis := referencedGenesis()
+			tt.modify(genesis)
+			var rules, paths []string
+			for _, v := range CheckReferences(genesis) {
+				rules = append(rules, v.Rule)
+				paths = append(paths, v.Path)
+			}
+			if !reflect.DeepEqual(rules, tt.rules) || !reflect.DeepEqual(paths, tt.paths) {
+				t.Errorf("CheckReferences() violations %v at %v, want %v at %v", rules, paths, tt.rules, tt.paths)
+			}
+		})
+	}
+}
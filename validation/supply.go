@@ -0,0 +1,135 @@
+package validation
+
+import (
+	"fmt"
+	"github.com/MinterTeam/explorer-genesis-uploader/domain"
+	"math/big"
+)
+
+const baseCoinId = 0
+
+// supply is a coin volume split by holders
+type supply struct {
+	accounts    *big.Int
+	stakes      *big.Int
+	updates     *big.Int
+	waitlist    *big.Int
+	frozenFunds *big.Int
+	pools       *big.Int
+	orders      *big.Int
+	reserves    *big.Int
+}
+
+func newSupply() *supply {
+	return &supply{
+		accounts:    new(big.Int),
+		stakes:      new(big.Int),
+		updates:     new(big.Int),
+		waitlist:    new(big.Int),
+		frozenFunds: new(big.Int),
+		pools:       new(big.Int),
+		orders:      new(big.Int),
+		reserves:    new(big.Int),
+	}
+}
+
+func (s *supply) total() *big.Int {
+	total := new(big.Int)
+	for _, v := range []*big.Int{s.accounts, s.stakes, s.updates, s.waitlist, s.frozenFunds, s.pools, s.orders, s.reserves} {
+		total.Add(total, v)
+	}
+	return total
+}
+
+// CheckSupply checks that volume of every coin equals to the sum of account
+// balances, candidate stakes and pending stake updates, waitlist, frozen
+// funds, pool reserves and volumes locked in limit orders. Reserves of coins
+// with crr > 0 are held in the base coin: they must be positive and are added
+// to its sum whether or not genesis lists coin 0. Genesis declares volume of
+// the base coin only when it lists coin 0, then the coin must have baseCoin
+// symbol. No other coin may use baseCoin symbol.
+func CheckSupply(genesis *domain.Genesis, baseCoin string) []Violation {
+	c := new(checker)
+	supplies := make(map[uint64]*supply)
+	get := func(coin uint64) *supply {
+		s, ok := supplies[coin]
+		if !ok {
+			s = newSupply()
+			supplies[coin] = s
+		}
+		return s
+	}
+
+	for i, account := range genesis.AppState.Accounts {
+		for j, b := range account.Balance {
+			c.add(get(b.Coin).accounts, fmt.Sprintf("app_state.accounts[%d].balance[%d].value", i, j), b.Value)
+		}
+	}
+	for i, candidate := range genesis.AppState.Candidates {
+		for j, s := range candidate.Stakes {
+			c.add(get(s.Coin).stakes, fmt.Sprintf("app_state.candidates[%d].stakes[%d].value", i, j), s.Value)
+		}
+		for j, s := range candidate.Updates {
+			c.add(get(s.Coin).updates, fmt.Sprintf("app_state.candidates[%d].updates[%d].value", i, j), s.Value)
+		}
+	}
+	for i, w := range genesis.AppState.Waitlist {
+		c.add(get(w.Coin).waitlist, fmt.Sprintf("app_state.waitlist[%d].value", i), w.Value)
+	}
+	for i, f := range genesis.AppState.FrozenFunds {
+		c.add(get(f.Coin).frozenFunds, fmt.Sprintf("app_state.frozen_funds[%d].value", i), f.Value)
+	}
+	for i, p := range genesis.AppState.Pools {
+		c.add(get(p.Coin0).pools, fmt.Sprintf("app_state.pools[%d].reserve0", i), p.Reserve0)
+		c.add(get(p.Coin1).pools, fmt.Sprintf("app_state.pools[%d].reserve1", i), p.Reserve1)
+		for j, o := range p.Orders {
+			if o.IsSale {
+				c.add(get(p.Coin0).orders, fmt.Sprintf("app_state.pools[%d].orders[%d].volume0", i, j), o.Volume0)
+			} else {
+				c.add(get(p.Coin1).orders, fmt.Sprintf("app_state.pools[%d].orders[%d].volume1", i, j), o.Volume1)
+			}
+		}
+	}
+
+	for i, coin := range genesis.AppState.Coins {
+		path := fmt.Sprintf("app_state.coins[%d].symbol", i)
+		switch {
+		case coin.ID == baseCoinId && coin.Symbol != baseCoin:
+			c.violate(RuleSupply, path, fmt.Sprintf("base coin has symbol %s, %s is configured", coin.Symbol, baseCoin))
+		case coin.ID != baseCoinId && coin.Symbol == baseCoin:
+			c.violate(RuleDuplicate, path, fmt.Sprintf("coin %d uses symbol %s of the base coin", coin.ID, baseCoin))
+		}
+		if coin.Crr == 0 || coin.ID == baseCoinId {
+			continue
+		}
+		path = fmt.Sprintf("app_state.coins[%d].reserve", i)
+		reserve, ok := c.parse(path, coin.Reserve)
+		if !ok {
+			continue
+		}
+		if reserve.Sign() == 0 {
+			c.violate(RuleSupply, path, fmt.Sprintf("coin %d (%s) has crr %d and no base coin reserve", coin.ID, coin.Symbol, coin.Crr))
+		}
+		base := get(baseCoinId)
+		base.reserves.Add(base.reserves, reserve)
+	}
+
+	for i, coin := range genesis.AppState.Coins {
+		path := fmt.Sprintf("app_state.coins[%d].volume", i)
+		volume, ok := c.parse(path, coin.Volume)
+		if !ok {
+			continue
+		}
+		s := get(coin.ID)
+		total := s.total()
+		if volume.Cmp(total) == 0 {
+			continue
+		}
+		c.violate(RuleSupply, path, fmt.Sprintf(
+			"coin %d (%s) volume %s != %s: accounts %s, stakes %s, updates %s, waitlist %s, frozen funds %s, pools %s, orders %s, coin reserves %s",
+			coin.ID, coin.Symbol, volume, total, s.accounts, s.stakes, s.updates, s.waitlist, s.frozenFunds, s.pools, s.orders, s.reserves,
+		))
+	}
+
+	return c.violations
+}
@@ -0,0 +1,117 @@
+package validation
+
+import (
+	"github.com/MinterTeam/explorer-genesis-uploader/domain"
+	"reflect"
+	"testing"
+)
+
+const testAddress = "Mx0000000000000000000000000000000000000001"
+
+// balancedGenesis holds every coin in every place CheckSupply sums
+func balancedGenesis() *domain.Genesis {
+	genesis := new(domain.Genesis)
+	genesis.AppState.Coins = []domain.GenesisCoin{
+		{ID: 1, Symbol: "TEST", Volume: "100", Crr: 50, Reserve: "40"},
+		{ID: 2, Symbol: "FLAT", Volume: "7"},
+	}
+	genesis.AppState.Accounts = []domain.Account{
+		{Address: testAddress, Balance: []domain.GenesisBalance{{Coin: 0, Value: "1000"}, {Coin: 1, Value: "10"}, {Coin: 2, Value: "7"}}},
+	}
+	genesis.AppState.Candidates = []domain.Candidate{{
+		ID:      1,
+		Stakes:  []domain.GenesisStake{{Owner: testAddress, Coin: 1, Value: "20"}},
+		Updates: []domain.GenesisStake{{Owner: testAddress, Coin: 1, Value: "30"}},
+	}}
+	genesis.AppState.Waitlist = []domain.Waitlist{{Owner: testAddress, Coin: 1, Value: "5"}}
+	genesis.AppState.FrozenFunds = []domain.FrozenFund{{Address: testAddress, Coin: 1, Value: "5"}}
+	genesis.AppState.Pools = []domain.Pool{{
+		ID: 1, Coin0: 0, Coin1: 1, Reserve0: "100", Reserve1: "20",
+		Orders: []domain.GenesisOrder{
+			{Id: 1, IsSale: true, Volume0: "60", Volume1: "1000"},
+			{Id: 2, IsSale: false, Volume0: "1000", Volume1: "10"},
+		},
+	}}
+	return genesis
+}
+
+func TestCheckSupply(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*domain.Genesis)
+		rules  []string
+		paths  []string
+	}{
+		{"balanced", func(*domain.Genesis) {}, nil, nil},
+		{
+			"pending stake updates are counted",
+			func(g *domain.Genesis) { g.AppState.Candidates[0].Updates = nil },
+			[]string{RuleSupply}, []string{"app_state.coins[0].volume"},
+		},
+		{
+			"volume mismatch",
+			func(g *domain.Genesis) { g.AppState.Coins[1].Volume = "8" },
+			[]string{RuleSupply}, []string{"app_state.coins[1].volume"},
+		},
+		{
+			"malformed amount",
+			func(g *domain.Genesis) { g.AppState.Waitlist[0].Value = "5.0" },
+			[]string{RuleAmount, RuleSupply}, []string{"app_state.waitlist[0].value", "app_state.coins[0].volume"},
+		},
+		{
+			"base coin with coin reserves",
+			func(g *domain.Genesis) {
+				g.AppState.Coins = append(g.AppState.Coins, domain.GenesisCoin{ID: 0, Symbol: "BIP", Volume: "1200"})
+			},
+			nil, nil,
+		},
+		{
+			"base coin without coin reserves",
+			func(g *domain.Genesis) {
+				g.AppState.Coins = append(g.AppState.Coins, domain.GenesisCoin{ID: 0, Symbol: "BIP", Volume: "1160"})
+			},
+			[]string{RuleSupply}, []string{"app_state.coins[2].volume"},
+		},
+		{
+			"base coin symbol",
+			func(g *domain.Genesis) {
+				g.AppState.Coins = append(g.AppState.Coins, domain.GenesisCoin{ID: 0, Symbol: "MNT", Volume: "1200"})
+			},
+			[]string{RuleSupply}, []string{"app_state.coins[2].symbol"},
+		},
+		{
+			"base coin symbol used by another coin",
+			func(g *domain.Genesis) { g.AppState.Coins[1].Symbol = "BIP" },
+			[]string{RuleDuplicate}, []string{"app_state.coins[1].symbol"},
+		},
+		{
+			"unlisted base coin reserve is checked",
+			func(g *domain.Genesis) { g.AppState.Coins[0].Reserve = "0" },
+			[]string{RuleSupply}, []string{"app_state.coins[0].reserve"},
+		},
+		{
+			"unlisted base coin reserve is parsed",
+			func(g *domain.Genesis) { g.AppState.Coins[0].Reserve = "-40" },
+			[]string{RuleAmount}, []string{"app_state.coins[0].reserve"},
+		},
+		{
+			"reserve of coin without crr is ignored",
+			func(g *domain.Genesis) { g.AppState.Coins[1].Reserve = "abc" },
+			nil, nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			genesis := balancedGenesis()
+			tt.modify(genesis)
+			var rules, paths []string
+			for _, v := range CheckSupply(genesis, "BIP") {
+				rules = append(rules, v.Rule)
+				paths = append(paths, v.Path)
+			}
+			if !reflect.DeepEqual(rules, tt.rules) || !reflect.DeepEqual(paths, tt.paths) {
+				t.Errorf("CheckSupply() violations %v at %v, want %v at %v", rules, paths, tt.rules, tt.paths)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package validation
+
+import (
+	"fmt"
+	"github.com/MinterTeam/explorer-genesis-uploader/domain"
+	"math/big"
+)
+
+const (
+	RuleAmount    = "amount"
+	RuleSupply    = "supply"
+	RuleFormat    = "format"
+	RuleDuplicate = "duplicate"
+	RuleReference = "reference"
+)
+
+// Violation is a genesis invariant that does not hold
+type Violation struct {
+	Rule    string `json:"rule"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (v Violation) Error() string {
+	return fmt.Sprintf("%s: %s: %s", v.Rule, v.Path, v.Message)
+}
+
+// Check runs every genesis check. Supply is checked only when identifiers and amounts
+// are well-formed, sums of coins which genesis does not define are not compared anyway,
+// so reference violations do not prevent it.
+func Check(genesis *domain.Genesis, baseCoin string) []Violation {
+	violations := CheckReferences(genesis)
+	for _, v := range violations {
+		if v.Rule != RuleReference {
+			return violations
+		}
+	}
+	return append(violations, CheckSupply(genesis, baseCoin)...)
+}
+
+type checker struct {
+	violations []Violation
+}
+
+func (c *checker) violate(rule, path, message string) {
+	c.violations = append(c.violations, Violation{Rule: rule, Path: path, Message: message})
+}
+
+func (c *checker) parse(path, value string) (*big.Int, bool) {
+	v, ok := new(big.Int).SetString(value, 10)
+	if !ok || v.Sign() < 0 {
+		c.violate(RuleAmount, path, fmt.Sprintf("invalid amount %q", value))
+		return nil, false
+	}
+	return v, true
+}
+
+func (c *checker) add(sum *big.Int, path, value string) {
+	if v, ok := c.parse(path, value); ok {
+		sum.Add(sum, v)
+	}
+}